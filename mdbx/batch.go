@@ -0,0 +1,115 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import "unsafe"
+
+// KV is a key/value pair read by Cursor.GetBatch.  Key and Val are zero-copy
+// views into the memory-mapped file, with the lifetime described at
+// Cursor.Get.
+type KV struct {
+	Key []byte
+	Val []byte
+}
+
+// GetBatch reads up to len(buf) consecutive key/value pairs into buf with a
+// single call into libmdbx and returns the number of pairs read.  last
+// reports that the end of the table was reached, so that no pairs are left
+// after buf[:n].
+//
+// The batch starts with the pair at the cursor position, not the one after
+// it, and the cursor is left at the first pair not read.  op is either First,
+// which first moves an unpositioned or exhausted cursor to the first pair, or
+// Next, which returns ErrNoData for an unpositioned cursor and ErrNotFound
+// once the table is exhausted.  An empty table returns ErrNotFound for First.
+//
+// GetBatch supports only tables without DupSort, for others it returns
+// Incompatible.  buf must hold at least 2 pairs.
+//
+// See mdbx_cursor_get_batch.
+func (c *Cursor) GetBatch(op uint, buf []KV) (n int, last bool, err error) {
+	limit := 2 * len(buf)
+	if cap(c.batch) < limit {
+		c.batch = make([]C.MDBX_val, limit)
+	}
+	var pairs *C.char
+	if limit > 0 {
+		pairs = (*C.char)(unsafe.Pointer(&c.batch[0]))
+	}
+	r := C.mdbxgo_cursor_get_batch(c._c, pairs, C.size_t(limit), C.MDBX_cursor_op(op))
+	n = int(r.val) / 2
+	for i := range n {
+		buf[i] = KV{Key: castToBytes(&c.batch[2*i]), Val: castToBytes(&c.batch[2*i+1])}
+	}
+	if r.err == C.MDBX_RESULT_TRUE {
+		return n, true, nil
+	}
+	return n, false, c.operrno("mdbx_cursor_get_batch", r.err)
+}
+
+// BatchIter iterates over the pairs of a table reading them in chunks with
+// Cursor.GetBatch, which makes a scan cost one cgo call per chunk rather
+// than per pair.
+//
+//	it := cur.Batches(256)
+//	for it.Next() {
+//		process(it.Key(), it.Val())
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type BatchIter struct {
+	cur   *Cursor
+	op    uint
+	buf   []KV
+	chunk []KV
+	last  bool
+	err   error
+}
+
+// Batches returns an iterator over the pairs from the cursor position to the
+// end of the table, or over the whole table if c is unpositioned, reading
+// size pairs per chunk.  size is raised to 2 if it is smaller.  c must not be
+// moved while the iterator is in use.
+func (c *Cursor) Batches(size int) *BatchIter {
+	return &BatchIter{cur: c, op: First, buf: make([]KV, max(size, 2))}
+}
+
+// Next advances to the next pair, reading another chunk if needed.  It
+// returns false at the end of the table or if an error occurred.
+func (it *BatchIter) Next() bool {
+	if len(it.chunk) > 1 {
+		it.chunk = it.chunk[1:]
+		return true
+	}
+	it.chunk = nil
+	if it.last || it.err != nil {
+		return false
+	}
+	n, last, err := it.cur.GetBatch(it.op, it.buf)
+	it.op = Next
+	it.last = last
+	switch {
+	case IsNotFound(err):
+		it.last = true
+	case err != nil:
+		it.err = err
+	}
+	if n == 0 {
+		return false
+	}
+	it.chunk = it.buf[:n]
+	return true
+}
+
+// Key returns the key of the current pair.
+func (it *BatchIter) Key() []byte { return it.chunk[0].Key }
+
+// Val returns the value of the current pair.
+func (it *BatchIter) Val() []byte { return it.chunk[0].Val }
+
+// Err returns the error that ended the iteration, if any.
+func (it *BatchIter) Err() error { return it.err }
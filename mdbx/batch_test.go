@@ -0,0 +1,215 @@
+package mdbx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"syscall"
+	"testing"
+)
+
+func TestCursor_GetBatch(t *testing.T) {
+	env, _ := setup(t)
+	const n = 1000
+	db := mustPutSeqBE(t, env, "batch", n)
+	empty := mustOpenUniqueDB(t, env, "empty")
+	dups := mustOpenDupSortDB(t, env, "dups")
+
+	err := env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		if _, _, err = cur.GetBatch(Next, make([]KV, 8)); !errors.Is(err, ErrNoData) {
+			t.Errorf("unexpected error of an unpositioned cursor: %v", err)
+		}
+
+		buf := make([]KV, 64)
+		var got, chunks int
+		for op, last := uint(First), false; !last; op = Next {
+			var k int
+			k, last, err = cur.GetBatch(op, buf)
+			if err != nil {
+				return err
+			}
+			for _, kv := range buf[:k] {
+				if !bytes.Equal(kv.Key, beKey(uint32(got))) || !bytes.Equal(kv.Val, kv.Key) {
+					t.Fatalf("pair %d: %x=%x", got, kv.Key, kv.Val)
+				}
+				got++
+			}
+			chunks++
+		}
+		if got != n || chunks != (n+len(buf)-1)/len(buf) {
+			t.Errorf("read %d pairs in %d chunks", got, chunks)
+		}
+		if _, _, err = cur.GetBatch(Next, buf); !IsNotFound(err) {
+			t.Errorf("unexpected error after the last chunk: %v", err)
+		}
+
+		// a positioned cursor continues at its position.
+		if _, _, err = cur.Get(beKey(n-3), nil, SetKey); err != nil {
+			return err
+		}
+		k, last, err := cur.GetBatch(Next, buf)
+		if err != nil || !last || k != 3 || !bytes.Equal(buf[0].Key, beKey(n-3)) {
+			t.Errorf("unexpected batch: %d %t %v", k, last, err)
+		}
+
+		if _, _, err = cur.GetBatch(First, buf[:1]); !IsErrnoSys(err, syscall.EINVAL) {
+			t.Errorf("unexpected error for a short buffer: %v", err)
+		}
+
+		for dbi, want := range map[DBI]func(error) bool{
+			empty: IsNotFound,
+			dups:  func(err error) bool { return errors.Is(err, Incompatible) },
+		} {
+			c, err := txn.OpenCursor(dbi)
+			if err != nil {
+				return err
+			}
+			if _, _, err = c.GetBatch(First, buf); !want(err) {
+				t.Errorf("dbi %d: unexpected error: %v", dbi, err)
+			}
+			c.Close()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCursor_Batches(t *testing.T) {
+	env, _ := setup(t)
+	const n = 1000
+	db := mustPutSeqBE(t, env, "batch", n)
+	empty := mustOpenUniqueDB(t, env, "empty")
+
+	err := env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for _, size := range []int{0, 7, n, 2 * n} {
+			if _, _, err = cur.Get(nil, nil, First); err != nil {
+				return err
+			}
+			var got int
+			it := cur.Batches(size)
+			for it.Next() {
+				if !bytes.Equal(it.Key(), beKey(uint32(got))) || !bytes.Equal(it.Val(), it.Key()) {
+					t.Fatalf("size %d, pair %d: %x=%x", size, got, it.Key(), it.Val())
+				}
+				got++
+			}
+			if it.Err() != nil || got != n {
+				t.Errorf("size %d: read %d pairs: %v", size, got, it.Err())
+			}
+		}
+
+		// start in the middle.
+		if _, _, err = cur.Get(beKey(n/2), nil, SetKey); err != nil {
+			return err
+		}
+		var got int
+		for it := cur.Batches(16); it.Next(); got++ {
+			if want := beKey(uint32(n/2 + got)); !bytes.Equal(it.Key(), want) {
+				t.Fatalf("pair %d: %x (!= %x)", got, it.Key(), want)
+			}
+		}
+		if got != n/2 {
+			t.Errorf("read %d pairs", got)
+		}
+
+		c, err := txn.OpenCursor(empty)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		it := c.Batches(16)
+		if it.Next() || it.Err() != nil {
+			t.Errorf("empty table iterated: %v", it.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkCursor_scan(b *testing.B) {
+	env, _ := setup(b)
+	var db DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		db, err = txn.OpenDBISimple("scan", Create)
+		if err != nil {
+			return err
+		}
+		var k [8]byte
+		for i := range 100000 {
+			binary.BigEndian.PutUint64(k[:], uint64(i))
+			if err = txn.Put(db, k[:], k[:], 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	scan := func(b *testing.B, read func(cur *Cursor) (int, error)) {
+		err := env.View(func(txn *Txn) error {
+			cur, err := txn.OpenCursor(db)
+			if err != nil {
+				return err
+			}
+			defer cur.Close()
+			b.ResetTimer()
+			for range b.N {
+				n, err := read(cur)
+				if err != nil {
+					return err
+				}
+				if n != 100000 {
+					b.Fatalf("read %d pairs", n)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("Get", func(b *testing.B) {
+		scan(b, func(cur *Cursor) (n int, err error) {
+			for op := uint(First); ; op = Next {
+				if _, _, err = cur.Get(nil, nil, op); err != nil {
+					if IsNotFound(err) {
+						return n, nil
+					}
+					return n, err
+				}
+				n++
+			}
+		})
+	})
+	b.Run("Batches", func(b *testing.B) {
+		scan(b, func(cur *Cursor) (n int, err error) {
+			if _, _, err = cur.Get(nil, nil, First); err != nil {
+				return 0, err
+			}
+			it := cur.Batches(256)
+			for it.Next() {
+				n++
+			}
+			return n, it.Err()
+		})
+	})
+}
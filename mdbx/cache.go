@@ -0,0 +1,112 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"strconv"
+	"unsafe"
+)
+
+// CacheStatus tells how CachedKey.Get used its cache entry.
+//
+// See MDBX_cache_status_t.
+type CacheStatus int
+
+// Statuses of CachedKey.Get.  CacheHit and CacheConfirmed are hits, the
+// value was found without a full B-tree search.  CacheRefreshed is a miss
+// that updated the cache entry.  The other statuses are misses that left it
+// alone.
+const (
+	CacheError     CacheStatus = C.MDBX_CACHE_ERROR     // An error other than ErrNotFound occurred.
+	CacheBehind    CacheStatus = C.MDBX_CACHE_BEHIND    // The cache entry is newer than the snapshot of the transaction.
+	CacheUnable    CacheStatus = C.MDBX_CACHE_UNABLE    // The history of the key does not allow caching it.
+	CacheRace      CacheStatus = C.MDBX_CACHE_RACE      // The cache entry is being updated by another thread.
+	CacheDirty     CacheStatus = C.MDBX_CACHE_DIRTY     // The value is on a page modified by the write transaction.
+	CacheHit       CacheStatus = C.MDBX_CACHE_HIT       // The cache entry is up to date.
+	CacheConfirmed CacheStatus = C.MDBX_CACHE_CONFIRMED // The cache entry was confirmed for newer transactions.
+	CacheRefreshed CacheStatus = C.MDBX_CACHE_REFRESHED // The value was searched and the cache entry updated.
+)
+
+// Hit reports whether s is CacheHit or CacheConfirmed.
+func (s CacheStatus) Hit() bool { return s == CacheHit || s == CacheConfirmed }
+
+func (s CacheStatus) String() string {
+	switch s {
+	case CacheError:
+		return "error"
+	case CacheBehind:
+		return "behind"
+	case CacheUnable:
+		return "unable"
+	case CacheRace:
+		return "race"
+	case CacheDirty:
+		return "dirty"
+	case CacheHit:
+		return "hit"
+	case CacheConfirmed:
+		return "confirmed"
+	case CacheRefreshed:
+		return "refreshed"
+	default:
+		return "CacheStatus(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// CachedKey looks up a key that is read over and over again, e.g. a
+// configuration entry.  It remembers where the value was found, so that
+// later lookups stop the B-tree search at the first page that has not
+// changed since, or skip it altogether if nothing has changed.
+//
+// A CachedKey may be used by any number of transactions, also concurrently,
+// as long as they read the same table through dbi.  Use Reset if dbi gets
+// reopened for another table.
+//
+// See MDBX_cache_entry_t.
+type CachedKey struct {
+	noCopy noCopy
+	dbi    DBI
+	key    []byte
+	entry  C.MDBX_cache_entry_t
+}
+
+// NewCachedKey returns a CachedKey for key in dbi.  key is copied.
+func NewCachedKey(dbi DBI, key []byte) *CachedKey {
+	ck := &CachedKey{dbi: dbi, key: append([]byte(nil), key...)}
+	C.mdbxgo_cache_init(&ck.entry)
+	return ck
+}
+
+// DBI returns the table of ck.
+func (ck *CachedKey) DBI() DBI { return ck.dbi }
+
+// Key returns the key of ck, which must not be modified.
+func (ck *CachedKey) Key() []byte { return ck.key }
+
+// Get retrieves the value of the key in txn like Txn.Get, with the same
+// lifetime of the returned slice, and reports how the cache was used.  If
+// the key is absent the error is ErrNotFound, and the absence is cached
+// too.
+//
+// See mdbx_cache_get.
+func (ck *CachedKey) Get(txn *Txn) ([]byte, CacheStatus, error) {
+	var k *C.char
+	if len(ck.key) > 0 {
+		k = (*C.char)(unsafe.Pointer(&ck.key[0]))
+	}
+	r := C.mdbxgo_cache_get(txn._txn, C.MDBX_dbi(ck.dbi), k, C.size_t(len(ck.key)), &ck.entry)
+	if r.err != success {
+		return nil, CacheStatus(r.status), txn.operrno("mdbx_cache_get", r.err)
+	}
+	return castToBytesRaw(unsafe.Pointer(r.vbase), r.vlen), CacheStatus(r.status), nil
+}
+
+// Reset forgets the cached position of the value.
+//
+// See mdbx_cache_init.
+func (ck *CachedKey) Reset() {
+	C.mdbxgo_cache_init(&ck.entry)
+}
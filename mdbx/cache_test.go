@@ -0,0 +1,122 @@
+package mdbx
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCachedKey(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenUniqueDB(t, env, "cache")
+	put := func(key, val string) {
+		t.Helper()
+		if err := env.Update(func(txn *Txn) error { return txn.Put(db, []byte(key), []byte(val), 0) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get := func(ck *CachedKey) (val string, status CacheStatus, err error) {
+		err = env.View(func(txn *Txn) error {
+			v, s, err := ck.Get(txn)
+			val, status = string(v), s
+			return err
+		})
+		return val, status, err
+	}
+
+	for i := range 100 {
+		put(fmt.Sprintf("key-%03d", i), "value")
+	}
+	put("hot", "v1")
+
+	ck := NewCachedKey(db, []byte("hot"))
+	for i, want := range []CacheStatus{CacheRefreshed, CacheHit} {
+		if v, s, err := get(ck); err != nil || v != "v1" || s != want {
+			t.Errorf("get %d: %q %s %v (!= %s)", i, v, s, err, want)
+		}
+	}
+
+	// another key of the table changes.
+	put("key-000", "changed")
+	if v, s, err := get(ck); err != nil || v != "v1" || s == CacheError {
+		t.Errorf("get after an unrelated change: %q %s %v", v, s, err)
+	}
+
+	put("hot", "v2")
+	if v, s, err := get(ck); err != nil || v != "v2" || s != CacheRefreshed {
+		t.Errorf("get after a change: %q %s %v", v, s, err)
+	}
+
+	err := env.Update(func(txn *Txn) error {
+		if err := txn.Put(db, []byte("hot"), []byte("v3"), 0); err != nil {
+			return err
+		}
+		v, s, err := ck.Get(txn)
+		if err != nil || string(v) != "v3" || s != CacheDirty {
+			t.Errorf("get in a write txn: %q %s %v", v, s, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	absent := NewCachedKey(db, []byte("absent"))
+	for range 2 {
+		if _, s, err := get(absent); !IsNotFound(err) || s == CacheError {
+			t.Errorf("get absent: %s %v", s, err)
+		}
+	}
+
+	ck.Reset()
+	if v, s, err := get(ck); err != nil || v != "v3" || s != CacheRefreshed {
+		t.Errorf("get after reset: %q %s %v", v, s, err)
+	}
+}
+
+func BenchmarkCachedKey(b *testing.B) {
+	env, _ := setup(b)
+	var db DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		if db, err = txn.OpenDBISimple("cache", Create); err != nil {
+			return err
+		}
+		for i := range 100000 {
+			if err = txn.Put(db, fmt.Appendf(nil, "key-%06d", i), []byte("value"), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	key := []byte("key-050000")
+
+	bench := func(b *testing.B, get func(txn *Txn) error) {
+		err := env.View(func(txn *Txn) error {
+			b.ResetTimer()
+			for range b.N {
+				if err := get(txn); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.Run("Get", func(b *testing.B) {
+		bench(b, func(txn *Txn) error {
+			_, err := txn.Get(db, key)
+			return err
+		})
+	})
+	b.Run("CachedKey", func(b *testing.B) {
+		ck := NewCachedKey(db, key)
+		bench(b, func(txn *Txn) error {
+			_, _, err := ck.Get(txn)
+			return err
+		})
+	})
+}
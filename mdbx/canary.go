@@ -0,0 +1,69 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+// Canary holds the four markers libmdbx keeps in the meta page, which are
+// committed atomically with the data.  X, Y and Z are free for the
+// application, e.g. for the last applied block and a schema version.  V is
+// the ID of the transaction that last changed them.
+//
+// See MDBX_canary.
+type Canary struct {
+	X, Y, Z uint64
+	V       uint64
+}
+
+// PutCanary sets the X, Y and Z markers, ignoring c.V.  If any of them
+// changes, V is set to the ID of txn.  The markers become visible to other
+// transactions when txn commits.
+//
+// See mdbx_canary_put.
+func (txn *Txn) PutCanary(c Canary) error {
+	canary := C.MDBX_canary{x: C.uint64_t(c.X), y: C.uint64_t(c.Y), z: C.uint64_t(c.Z)}
+	ret := C.mdbx_canary_put(txn._txn, &canary)
+	return txn.operrno("mdbx_canary_put", ret)
+}
+
+// TouchCanary sets the V marker to the ID of txn without changing the
+// others.
+//
+// See mdbx_canary_put.
+func (txn *Txn) TouchCanary() error {
+	ret := C.mdbx_canary_put(txn._txn, nil)
+	return txn.operrno("mdbx_canary_put", ret)
+}
+
+// Canary returns the markers as seen by txn.
+//
+// See mdbx_canary_get.
+func (txn *Txn) Canary() (Canary, error) {
+	var canary C.MDBX_canary
+	ret := C.mdbx_canary_get(txn._txn, &canary)
+	if ret != success {
+		return Canary{}, txn.operrno("mdbx_canary_get", ret)
+	}
+	return castCanary(&canary), nil
+}
+
+// Canary returns the markers of the last committed transaction.  libmdbx
+// reads them through a read transaction, which Canary begins and aborts
+// within a single call into C, so it costs much less than a View.
+//
+// See mdbx_canary_get.
+func (env *Env) Canary() (Canary, error) {
+	if err := env.checkFork(); err != nil {
+		return Canary{}, err
+	}
+	r := C.mdbxgo_env_canary(env._env)
+	if r.err != success {
+		return Canary{}, operrno("mdbx_canary_get", r.err)
+	}
+	return castCanary(&r.canary), nil
+}
+
+func castCanary(c *C.MDBX_canary) Canary {
+	return Canary{X: uint64(c.x), Y: uint64(c.y), Z: uint64(c.z), V: uint64(c.v)}
+}
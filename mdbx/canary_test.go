@@ -0,0 +1,57 @@
+package mdbx
+
+import "testing"
+
+func TestCanary(t *testing.T) {
+	env, _ := setup(t)
+
+	if c, err := env.Canary(); err != nil || c != (Canary{}) {
+		t.Fatalf("initial canary: %+v %v", c, err)
+	}
+
+	var id uint64
+	err := env.Update(func(txn *Txn) error {
+		id = txn.ID()
+		if err := txn.PutCanary(Canary{X: 1, Y: 2, Z: 3, V: 42}); err != nil {
+			return err
+		}
+		c, err := txn.Canary()
+		if err != nil {
+			return err
+		}
+		if want := (Canary{X: 1, Y: 2, Z: 3, V: id}); c != want {
+			t.Errorf("canary in txn: %+v (!= %+v)", c, want)
+		}
+		// not visible before commit
+		if c, err = env.Canary(); err != nil || c != (Canary{}) {
+			t.Errorf("uncommitted canary visible: %+v %v", c, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Canary{X: 1, Y: 2, Z: 3, V: id}
+	if c, err := env.Canary(); err != nil || c != want {
+		t.Errorf("committed canary: %+v %v (!= %+v)", c, err, want)
+	}
+
+	// unchanged markers leave V alone, TouchCanary does not.
+	err = env.Update(func(txn *Txn) error {
+		if err := txn.PutCanary(Canary{X: 1, Y: 2, Z: 3}); err != nil {
+			return err
+		}
+		if c, err := txn.Canary(); err != nil || c != want {
+			t.Errorf("canary after unchanged put: %+v %v", c, err)
+		}
+		id = txn.ID()
+		return txn.TouchCanary()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.V = id
+	if c, err := env.Canary(); err != nil || c != want {
+		t.Errorf("touched canary: %+v %v (!= %+v)", c, err, want)
+	}
+}
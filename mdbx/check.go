@@ -0,0 +1,371 @@
+package mdbx
+
+/*
+#include <stdlib.h>
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"context"
+	"strconv"
+	"unsafe"
+)
+
+// Flags for CheckOptions.Flags.
+//
+// See MDBX_chk_flags_t.
+const (
+	CheckDefaults = C.MDBX_CHK_DEFAULTS
+	// CheckReadWrite holds the writer lock for the duration of the check, so
+	// that recent meta pages can be verified against the committed state.
+	// Write transactions are blocked meanwhile.
+	CheckReadWrite          = C.MDBX_CHK_READWRITE
+	CheckSkipBTreeTraversal = C.MDBX_CHK_SKIP_BTREE_TRAVERSAL // Skip the page tree crawl.
+	CheckSkipKVTraversal    = C.MDBX_CHK_SKIP_KV_TRAVERSAL    // Skip iterating key-value records.
+	// CheckIgnoreOrder skips the order checks of keys and values, which is
+	// required for tables opened with custom comparators.
+	CheckIgnoreOrder = C.MDBX_CHK_IGNORE_ORDER
+)
+
+// CheckSeverity is the severity of a CheckIssue, or the level of detail of
+// the lines passed to CheckOptions.Log.  Smaller values are more severe.
+//
+// See MDBX_chk_severity_t.
+type CheckSeverity uint8
+
+// Severities of the integrity check.
+const (
+	CheckFatal      CheckSeverity = C.MDBX_chk_fatal
+	CheckError      CheckSeverity = C.MDBX_chk_error
+	CheckWarning    CheckSeverity = C.MDBX_chk_warning
+	CheckNotice     CheckSeverity = C.MDBX_chk_notice
+	CheckResult     CheckSeverity = C.MDBX_chk_result
+	CheckResolution CheckSeverity = C.MDBX_chk_resolution
+	CheckProcessing CheckSeverity = C.MDBX_chk_processing
+	CheckInfo       CheckSeverity = C.MDBX_chk_info
+	CheckVerbose    CheckSeverity = C.MDBX_chk_verbose
+	CheckDetails    CheckSeverity = C.MDBX_chk_details
+	CheckExtra      CheckSeverity = C.MDBX_chk_extra
+)
+
+func (s CheckSeverity) prio() int { return int(s) >> C.MDBX_chk_severity_prio_shift }
+
+// String implements fmt.Stringer.
+func (s CheckSeverity) String() string {
+	switch s {
+	case CheckFatal:
+		return "fatal"
+	case CheckError:
+		return "error"
+	case CheckWarning:
+		return "warning"
+	case CheckNotice:
+		return "notice"
+	case CheckResult:
+		return "result"
+	case CheckResolution:
+		return "resolution"
+	case CheckProcessing:
+		return "processing"
+	case CheckInfo:
+		return "info"
+	case CheckVerbose:
+		return "verbose"
+	case CheckDetails:
+		return "details"
+	case CheckExtra:
+		return "extra"
+	default:
+		return "CheckSeverity(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// CheckStage is a stage of the integrity check.
+//
+// See MDBX_chk_stage_t.
+type CheckStage int
+
+// Stages of the integrity check, in the order they are performed.
+const (
+	CheckStageNone     CheckStage = C.MDBX_chk_none
+	CheckStageInit     CheckStage = C.MDBX_chk_init
+	CheckStageLock     CheckStage = C.MDBX_chk_lock
+	CheckStageMeta     CheckStage = C.MDBX_chk_meta
+	CheckStageTree     CheckStage = C.MDBX_chk_tree
+	CheckStageGC       CheckStage = C.MDBX_chk_gc
+	CheckStageSpace    CheckStage = C.MDBX_chk_space
+	CheckStageMainDB   CheckStage = C.MDBX_chk_maindb
+	CheckStageTables   CheckStage = C.MDBX_chk_tables
+	CheckStageConclude CheckStage = C.MDBX_chk_conclude
+	CheckStageUnlock   CheckStage = C.MDBX_chk_unlock
+	CheckStageFinalize CheckStage = C.MDBX_chk_finalize
+)
+
+// String implements fmt.Stringer.
+func (s CheckStage) String() string {
+	switch s {
+	case CheckStageNone:
+		return "none"
+	case CheckStageInit:
+		return "init"
+	case CheckStageLock:
+		return "lock"
+	case CheckStageMeta:
+		return "meta"
+	case CheckStageTree:
+		return "tree"
+	case CheckStageGC:
+		return "gc"
+	case CheckStageSpace:
+		return "space"
+	case CheckStageMainDB:
+		return "maindb"
+	case CheckStageTables:
+		return "tables"
+	case CheckStageConclude:
+		return "conclude"
+	case CheckStageUnlock:
+		return "unlock"
+	case CheckStageFinalize:
+		return "finalize"
+	default:
+		return "CheckStage(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// Pseudo-names of the core tables in CheckIssue.Table and CheckTable.Name,
+// spelled like mdbx_chk does.
+const (
+	CheckMainDB = "@MAIN"
+	CheckGCDB   = "@GC"
+	CheckMeta   = "@META"
+)
+
+// CheckOptions controls Env.Check.  The zero value performs a complete
+// read-only check.
+type CheckOptions struct {
+	// Flags is a combination of the Check* flags.
+	Flags uint
+	// Verbosity is the least severe level of the lines passed to Log.  It is
+	// ignored when Log is nil.
+	Verbosity CheckSeverity
+	// Log, if not nil, receives the lines of the human-readable report that
+	// mdbx_chk would print, up to Verbosity.  It is called on the goroutine
+	// running Env.Check.
+	Log func(severity CheckSeverity, stage CheckStage, line string)
+}
+
+// CheckIssue is a problem found by Env.Check.  Repeated occurrences of the
+// same problem are counted rather than reported one by one; Entry and Message
+// then describe the first of them.
+type CheckIssue struct {
+	Severity CheckSeverity
+	Stage    CheckStage
+	Table    string // Table being processed, if any
+	Object   string // Kind of the object at fault, e.g. "entry" or "page", if any
+	Entry    int64  // Number of the object at fault, or -1
+	Message  string
+	Count    int
+}
+
+// String implements fmt.Stringer.
+func (i CheckIssue) String() string {
+	s := i.Severity.String() + ": " + i.Stage.String()
+	if i.Table != "" {
+		s += " " + i.Table
+	}
+	if i.Object != "" {
+		s += " " + i.Object
+		if i.Entry >= 0 {
+			s += " #" + strconv.FormatInt(i.Entry, 10)
+		}
+	}
+	s += ": " + i.Message
+	if i.Count > 1 {
+		s += " (" + strconv.Itoa(i.Count) + " times)"
+	}
+	return s
+}
+
+// CheckPages counts the pages of a table found by the page tree crawl.
+type CheckPages struct {
+	All           uint64
+	Empty         uint64
+	Broken        uint64
+	Branch        uint64
+	Leaf          uint64
+	NestedBranch  uint64 // Branch pages of nested (DupSort) trees
+	NestedLeaf    uint64 // Leaf pages of nested (DupSort) trees
+	NestedSubLeaf uint64 // Leaf pages with nested (DupSort) sub-pages
+}
+
+// CheckTable summarizes a table processed by Env.Check.
+type CheckTable struct {
+	Name         string
+	Flags        uint
+	Entries      uint64 // Key-value pairs, counting every duplicate
+	Keys         uint64 // Distinct keys
+	PayloadBytes uint64
+	LostBytes    uint64
+	Pages        CheckPages
+}
+
+// CheckGC reports the state of the garbage collector, i.e. the free list.
+type CheckGC struct {
+	Table CheckTable
+	// Pages is the number of pages listed in the GC.
+	Pages uint64
+	// Reclaimable is the number of pages in the GC that may be reused right
+	// away; the rest are retained for MVCC snapshots of readers.
+	Reclaimable uint64
+	// Problems is the number of problems found in the GC records or in the
+	// accounting of pages between the page trees and the GC.
+	Problems uint64
+}
+
+// Consistent reports whether the GC and the page trees account for every
+// allocated page exactly once.
+func (gc *CheckGC) Consistent() bool { return gc.Problems == 0 }
+
+// CheckProblems counts the problems found by Env.Check in each area.
+type CheckProblems struct {
+	Meta  uint64
+	Tree  uint64
+	GC    uint64
+	KV    uint64
+	Total uint64
+}
+
+// CheckReport is the outcome of Env.Check.
+type CheckReport struct {
+	// Metas describes the three meta pages and RecentTxnID is the ID of the
+	// most recent transaction they reference, as seen when the check ended.
+	Metas       [3]MetaInfo
+	RecentTxnID uint64
+
+	AllocatedPages uint64 // Pages below the allocation boundary
+	BackedPages    uint64 // Pages backed by the database file
+	UsedPages      uint64 // Pages reached by the page tree crawl
+	PayloadBytes   uint64
+	UnusedBytes    uint64
+
+	// Main is the main table, which also holds the records of named tables.
+	Main CheckTable
+	// Tables are the named tables, in the order they were processed.
+	Tables []CheckTable
+	GC     CheckGC
+
+	Problems CheckProblems
+	Issues   []CheckIssue
+}
+
+// OK reports whether the check found no problems.
+func (r *CheckReport) OK() bool { return r.Problems.Total == 0 }
+
+func (r *CheckReport) addIssue(issue CheckIssue) {
+	for i := range r.Issues {
+		prev := &r.Issues[i]
+		if prev.Severity == issue.Severity && prev.Stage == issue.Stage && prev.Table == issue.Table &&
+			prev.Object == issue.Object && prev.Message == issue.Message {
+			prev.Count++
+			return
+		}
+	}
+	issue.Count = 1
+	r.Issues = append(r.Issues, issue)
+}
+
+// Check verifies the integrity of the database of env, like the mdbx_chk tool
+// does, and returns a structured report of its findings.
+//
+// The check is performed in a read transaction of its own, so it runs
+// alongside readers and writers unless CheckReadWrite is given, and it is
+// subject to the usual limits of long-lived readers.  Tables with custom
+// comparators must be checked with CheckIgnoreOrder.
+//
+// A nil error means the check was completed, not that the database is sound;
+// see CheckReport.OK and CheckReport.Issues.  If ctx is cancelled the check is
+// interrupted and ctx.Err() is returned along with the partial report.
+//
+// See mdbx_env_chk.
+func (env *Env) Check(ctx context.Context, opts CheckOptions) (*CheckReport, error) {
+	if err := env.checkFork(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	report := &CheckReport{}
+	cctx, done := newCheckFunc(report, opts.Log)
+	defer done()
+
+	chk := C.mdbxgo_chk_new(C.size_t(cctx))
+	if chk == nil {
+		return nil, operrno("mdbx_env_chk", C.MDBX_ENOMEM)
+	}
+	defer C.free(unsafe.Pointer(chk))
+	checkctxs.get(cctx).chk = chk
+	stop := context.AfterFunc(ctx, func() { C.mdbxgo_chk_break(chk) })
+	defer stop()
+
+	verbosity := CheckWarning
+	if opts.Log != nil && opts.Verbosity > verbosity {
+		verbosity = opts.Verbosity
+	}
+	ret := C.mdbxgo_env_chk(env._env, chk, C.MDBX_chk_flags_t(opts.Flags), C.MDBX_chk_severity_t(verbosity))
+
+	r := &chk.ctx.result
+	report.AllocatedPages = uint64(r.alloc_pages)
+	report.BackedPages = uint64(r.backed_pages)
+	report.UsedPages = uint64(r.processed_pages)
+	report.PayloadBytes = uint64(r.total_payload_bytes)
+	report.UnusedBytes = uint64(r.total_unused_bytes)
+	report.GC.Pages = uint64(r.gc_pages)
+	report.GC.Reclaimable = uint64(r.reclaimable_pages)
+	report.GC.Problems = uint64(r.problems_gc)
+	report.Problems = CheckProblems{
+		Meta:  uint64(r.problems_meta),
+		Tree:  uint64(r.tree_problems),
+		GC:    uint64(r.problems_gc),
+		KV:    uint64(r.problems_kv),
+		Total: uint64(r.total_problems),
+	}
+	if info, err := env.Info(nil); err == nil {
+		report.Metas = info.Metas
+		report.RecentTxnID = info.RecentTxnID
+	}
+
+	if err := checkctxs.get(cctx).err; err != nil {
+		return report, err
+	}
+	if err := ctx.Err(); err != nil && (ret == C.MDBX_RESULT_TRUE || ret == C.MDBX_EINTR) {
+		return report, err
+	}
+	if ret == C.MDBX_PROBLEM || (ret != success && report.Problems.Total != 0) {
+		// the problems are detailed by the report.
+		return report, nil
+	}
+	return report, operrno("mdbx_env_chk", ret)
+}
+
+func castCheckTable(name string, tbl *C.MDBX_chk_table_t) CheckTable {
+	return CheckTable{
+		Name:         name,
+		Flags:        uint(tbl.flags),
+		Entries:      uint64(tbl.histogram.val_len.count),
+		Keys:         uint64(tbl.histogram.multival.count),
+		PayloadBytes: uint64(tbl.payload_bytes),
+		LostBytes:    uint64(tbl.lost_bytes),
+		Pages: CheckPages{
+			All:           uint64(tbl.pages.all),
+			Empty:         uint64(tbl.pages.empty),
+			Broken:        uint64(tbl.pages.broken),
+			Branch:        uint64(tbl.pages.branch),
+			Leaf:          uint64(tbl.pages.leaf),
+			NestedBranch:  uint64(tbl.pages.nested_branch),
+			NestedLeaf:    uint64(tbl.pages.nested_leaf),
+			NestedSubLeaf: uint64(tbl.pages.nested_subleaf),
+		},
+	}
+}
@@ -0,0 +1,173 @@
+package mdbx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnv_Check(t *testing.T) {
+	env, _ := setup(t)
+	err := env.Update(func(txn *Txn) error {
+		root, err := txn.OpenRoot(0)
+		if err != nil {
+			return err
+		}
+		dups, err := txn.OpenDBISimple("dups", Create|DupSort)
+		if err != nil {
+			return err
+		}
+		for i := range 100 {
+			if err = txn.Put(root, fmt.Appendf(nil, "key-%03d", i), []byte("value"), 0); err != nil {
+				return err
+			}
+		}
+		for i := range 10 {
+			for j := range 5 {
+				if err = txn.Put(dups, fmt.Appendf(nil, "key-%03d", i), fmt.Appendf(nil, "dup-%d", j), 0); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := env.Check(context.Background(), CheckOptions{})
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if !report.OK() || len(report.Issues) != 0 {
+		t.Fatalf("problems found: %+v %v", report.Problems, report.Issues)
+	}
+	if !report.GC.Consistent() {
+		t.Errorf("GC inconsistent: %+v", report.GC)
+	}
+
+	// the main table holds a record for the named table.
+	if report.Main.Name != CheckMainDB || report.Main.Entries != 101 || report.Main.Pages.All == 0 {
+		t.Errorf("unexpected main table: %+v", report.Main)
+	}
+	if report.GC.Table.Name != CheckGCDB {
+		t.Errorf("unexpected GC table: %+v", report.GC.Table)
+	}
+	if len(report.Tables) != 1 {
+		t.Fatalf("unexpected tables: %+v", report.Tables)
+	}
+	tbl := report.Tables[0]
+	if tbl.Name != "dups" || tbl.Flags&DupSort == 0 || tbl.Entries != 50 || tbl.Keys != 10 || tbl.Pages.All == 0 {
+		t.Errorf("unexpected table: %+v", tbl)
+	}
+	if report.UsedPages == 0 || report.UsedPages > report.AllocatedPages {
+		t.Errorf("unexpected page counts: used %d, allocated %d", report.UsedPages, report.AllocatedPages)
+	}
+
+	var steady bool
+	for _, meta := range report.Metas {
+		if meta.TxnID == report.RecentTxnID {
+			steady = meta.State() == MetaSteady
+		}
+	}
+	if !steady {
+		t.Errorf("recent meta page is not steady: %d %+v", report.RecentTxnID, report.Metas)
+	}
+}
+
+func TestEnv_Check_log(t *testing.T) {
+	env, _ := setupReaderInfoEnv(t)
+
+	var lines []string
+	_, err := env.Check(context.Background(), CheckOptions{
+		Flags:     CheckReadWrite,
+		Verbosity: CheckVerbose,
+		Log: func(severity CheckSeverity, stage CheckStage, line string) {
+			if severity > CheckVerbose {
+				t.Errorf("%s line logged: %s", severity, line)
+			}
+			lines = append(lines, stage.String()+": "+line)
+		},
+	})
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), "meta-0: ") {
+		t.Errorf("meta pages not logged:\n%s", strings.Join(lines, "\n"))
+	}
+
+	_, err = env.Check(context.Background(), CheckOptions{
+		Verbosity: CheckInfo,
+		Log: func(CheckSeverity, CheckStage, string) {
+			panic("stop check")
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "stop check") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestEnv_Check_cancel(t *testing.T) {
+	env, _ := setupReaderInfoEnv(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := env.Check(ctx, CheckOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestEnv_Check_corrupted(t *testing.T) {
+	env, dbi := setupReaderInfoEnv(t)
+
+	path := filepath.Join(t.TempDir(), "copy.mdbx")
+	if err := env.Copy(path, CopyCompact); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	info, err := env.Info(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the pages after the meta pages hold the b-tree of dbi, overwrite some
+	// of them.
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	garbage := make([]byte, 2*info.PageSize)
+	for i := range garbage {
+		garbage[i] = 0xA5
+	}
+	if _, err = f.WriteAt(garbage, int64(5*info.PageSize)); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	corrupted, err := NewEnv(Default)
+	if err != nil {
+		t.Fatalf("env: %v", err)
+	}
+	defer corrupted.Close()
+	if err = corrupted.Open(path, NoSubdir|Readonly, 0644); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	report, err := corrupted.Check(context.Background(), CheckOptions{})
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if report.OK() || len(report.Issues) == 0 {
+		t.Fatalf("corruption of dbi %d not detected: %+v", dbi, report)
+	}
+	for _, issue := range report.Issues {
+		if issue.Severity > CheckWarning || issue.Count < 1 {
+			t.Errorf("unexpected issue: %v", issue)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package mdbx
+
+/*
+#include <stdlib.h>
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// OpenDBIWithCompare opens a named table like OpenDBISimple, ordering its keys
+// by keyCmp and, in DupSort tables, the values of a key by dataCmp.  The
+// comparators follow the convention of bytes.Compare.  A nil comparator stands
+// for the one the table already has in env, or else the one its flags imply.
+//
+// CmpUint64BE, CmpDescending and CmpLengthPrefixed are run in C, other
+// comparators are called from C for every comparison, which makes lookups
+// two to three times slower.  The comparators must not retain their
+// arguments or use the Txn.  As libmdbx cannot fail a comparison, a panic in a
+// Go comparator is recovered and the pair is taken to be equal, which may
+// break the order of the table; every later commit of a Txn of env then
+// aborts the Txn and returns the panic.
+//
+// The order of a table is not stored in the database, so every process must
+// open it with the same comparators, before any other access, and the table
+// must be checked with CheckIgnoreOrder.  Once env has a table open, opening
+// it again with other comparators fails with EINVAL, apart from Go comparators,
+// which are ignored in favor of the ones given first.  Env.CloseDBI and
+// Env.Close release the comparators of a table.  Up to 128 tables with Go
+// comparators may be open at a time, in all Envs, beyond which
+// OpenDBIWithCompare fails with DBsFull.
+//
+// See mdbx_dbi_open_ex.
+func (txn *Txn) OpenDBIWithCompare(name string, flags uint, keyCmp, dataCmp func(a, b []byte) int) (DBI, error) {
+	kcmp, dcmp := cmpKind(keyCmp), cmpKind(dataCmp)
+	cmps := &txn.env.cmps
+	cmps.mu.Lock()
+	defer cmps.mu.Unlock()
+
+	// libmdbx closes the handle of a table opened with other comparators
+	// than it has, so they are checked here first.
+	tbl, ok := cmps.tables[name]
+	if ok {
+		if kcmp != C.MDBXGO_CMP_NONE && kcmp != tbl.kcmp || dcmp != C.MDBXGO_CMP_NONE && dcmp != tbl.dcmp {
+			return 0, txn.operrno("mdbx_dbi_open", C.MDBX_EINVAL)
+		}
+	} else {
+		tbl = &dbiCompare{slot: -1, kcmp: kcmp, dcmp: dcmp}
+		if kcmp == C.MDBXGO_CMP_GO || dcmp == C.MDBXGO_CMP_GO {
+			if tbl.slot = acquireCmpSlot(); tbl.slot < 0 {
+				return 0, &OpError{Op: "mdbx_dbi_open", Errno: DBsFull}
+			}
+			cmpSlots[tbl.slot].key.Store(&keyCmp)
+			cmpSlots[tbl.slot].data.Store(&dataCmp)
+			cmpSlots[tbl.slot].cmps.Store(cmps)
+		}
+	}
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	r := C.mdbxgo_dbi_open_cmp(txn._txn, cname, C.MDBX_db_flags_t(flags), tbl.kcmp, tbl.dcmp, tbl.slot)
+	if r.err != success {
+		if !ok && tbl.slot >= 0 {
+			releaseCmpSlot(tbl.slot)
+		}
+		return 0, txn.operrno("mdbx_dbi_open", r.err)
+	}
+	if cmps.tables == nil {
+		cmps.tables = map[string]*dbiCompare{}
+		cmps.names = map[DBI]string{}
+	}
+	cmps.tables[name] = tbl
+	cmps.names[DBI(r.val)] = name
+	return DBI(r.val), nil
+}
+
+// CmpUint64BE orders keys as big-endian unsigned integers, such as those of
+// binary.BigEndian.AppendUint64, by value.  The integers may have any length,
+// so keys may drop leading zero bytes to save space.  Encodings of the same
+// value order by length.  Passed to OpenDBIWithCompare, CmpUint64BE is run in
+// C.
+func CmpUint64BE(a, b []byte) int {
+	if len(a) == 8 && len(b) == 8 {
+		x, y := binary.BigEndian.Uint64(a), binary.BigEndian.Uint64(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	sa, sb := bytes.TrimLeft(a, "\x00"), bytes.TrimLeft(b, "\x00")
+	if len(sa) != len(sb) {
+		return cmpInt(len(sa), len(sb))
+	}
+	if c := bytes.Compare(sa, sb); c != 0 {
+		return c
+	}
+	return cmpInt(len(a), len(b))
+}
+
+// CmpDescending orders keys bytewise in descending order, the reverse of
+// bytes.Compare.  Unlike the ReverseKey flag it compares from the first byte
+// on.  Passed to OpenDBIWithCompare, CmpDescending is run in C.
+func CmpDescending(a, b []byte) int {
+	return bytes.Compare(b, a)
+}
+
+// CmpLengthPrefixed orders keys which start with a field prefixed by its
+// length, as written by binary.AppendUvarint, by the field and then by the
+// rest of the key.  So keys composed of a variable length string and another
+// field order by the string, as if it was not prefixed.  A key with a
+// malformed prefix is taken to be all field.  Passed to OpenDBIWithCompare,
+// CmpLengthPrefixed is run in C.
+func CmpLengthPrefixed(a, b []byte) int {
+	fa, sa := splitLengthPrefixed(a)
+	fb, sb := splitLengthPrefixed(b)
+	if c := bytes.Compare(fa, fb); c != 0 {
+		return c
+	}
+	if c := bytes.Compare(sa, sb); c != 0 {
+		return c
+	}
+	return bytes.Compare(a, b)
+}
+
+func splitLengthPrefixed(key []byte) (field, suffix []byte) {
+	n, h := binary.Uvarint(key)
+	if h <= 0 || n > uint64(len(key)-h) {
+		return key, nil
+	}
+	return key[h : h+int(n)], key[h+int(n):]
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// cmpBuiltins maps the entry points of the comparators with a C counterpart
+// to the counterpart.
+var cmpBuiltins = map[uintptr]C.int{
+	reflect.ValueOf(CmpUint64BE).Pointer():       C.MDBXGO_CMP_UINT64_BE,
+	reflect.ValueOf(CmpDescending).Pointer():     C.MDBXGO_CMP_DESCENDING,
+	reflect.ValueOf(CmpLengthPrefixed).Pointer(): C.MDBXGO_CMP_LENGTH_PREFIXED,
+}
+
+func cmpKind(cmp func(a, b []byte) int) C.int {
+	if cmp == nil {
+		return C.MDBXGO_CMP_NONE
+	}
+	if kind, ok := cmpBuiltins[reflect.ValueOf(cmp).Pointer()]; ok {
+		return kind
+	}
+	return C.MDBXGO_CMP_GO
+}
+
+// dbiComparators is the registry of the comparators of the tables of an Env
+// opened by OpenDBIWithCompare.  Tables are kept by name, so that reopening a
+// table passes libmdbx the same comparators, even after the handle was lost
+// to an aborted txn.
+type dbiComparators struct {
+	mu     sync.Mutex
+	tables map[string]*dbiCompare
+	names  map[DBI]string
+	err    atomic.Pointer[error] // the first failure of a Go comparator
+
+	// foreign holds the tables given a key comparator by OpenDBI.
+	foreign map[DBI]bool
+}
+
+type dbiCompare struct {
+	kcmp, dcmp C.int // MDBXGO_CMP_*
+	slot       C.int // -1 without Go comparators
+}
+
+// release forgets the table of dbi, or all tables if all is set, after
+// libmdbx closed them.
+func (cmps *dbiComparators) release(dbi DBI, all bool) {
+	cmps.mu.Lock()
+	defer cmps.mu.Unlock()
+	for d, name := range cmps.names {
+		if !all && d != dbi {
+			continue
+		}
+		if tbl := cmps.tables[name]; tbl != nil && tbl.slot >= 0 {
+			releaseCmpSlot(tbl.slot)
+		}
+		delete(cmps.tables, name)
+		delete(cmps.names, d)
+	}
+	if all {
+		cmps.foreign = nil
+	} else {
+		delete(cmps.foreign, dbi)
+	}
+}
+
+func (cmps *dbiComparators) setForeign(dbi DBI) {
+	cmps.mu.Lock()
+	defer cmps.mu.Unlock()
+	if cmps.foreign == nil {
+		cmps.foreign = map[DBI]bool{}
+	}
+	cmps.foreign[dbi] = true
+}
+
+// keyCmp reports whether dbi was given a key comparator, by OpenDBI or
+// OpenDBIWithCompare.
+func (cmps *dbiComparators) keyCmp(dbi DBI) bool {
+	cmps.mu.Lock()
+	defer cmps.mu.Unlock()
+	if cmps.foreign[dbi] {
+		return true
+	}
+	name, ok := cmps.names[dbi]
+	return ok && cmps.tables[name].kcmp != C.MDBXGO_CMP_NONE
+}
+
+// rename moves the comparators of dbi to the new name of its table.
+func (cmps *dbiComparators) rename(dbi DBI, name string) {
+	cmps.mu.Lock()
+	defer cmps.mu.Unlock()
+	old, ok := cmps.names[dbi]
+	if !ok {
+		return
+	}
+	cmps.tables[name] = cmps.tables[old]
+	delete(cmps.tables, old)
+	cmps.names[dbi] = name
+}
+
+// failure returns the first failure of a Go comparator of the tables, or nil.
+func (cmps *dbiComparators) failure() error {
+	if err := cmps.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+var errCmpReleased = errors.New("mdbx: comparator used after its table was closed")
+
+// cmpSlots holds the Go comparators called by the trampolines of each slot,
+// and the registry of the Env of their table.  The registry is kept when the
+// slot is released, for a late call to report to.
+var cmpSlots [C.MDBXGO_CMP_SLOTS]cmpSlot
+
+type cmpSlot struct {
+	key, data atomic.Pointer[func(a, b []byte) int]
+	cmps      atomic.Pointer[dbiComparators]
+}
+
+// fail caches err on the Env of the table of s, unless it has a failure.
+func (s *cmpSlot) fail(err error) {
+	if cmps := s.cmps.Load(); cmps != nil {
+		cmps.err.CompareAndSwap(nil, &err)
+	}
+}
+
+var cmpSlotsUsed [C.MDBXGO_CMP_SLOTS]bool
+var cmpSlotsLock sync.Mutex
+
+func acquireCmpSlot() C.int {
+	cmpSlotsLock.Lock()
+	defer cmpSlotsLock.Unlock()
+	for i, used := range cmpSlotsUsed {
+		if !used {
+			cmpSlotsUsed[i] = true
+			return C.int(i)
+		}
+	}
+	return -1
+}
+
+func releaseCmpSlot(slot C.int) {
+	cmpSlotsLock.Lock()
+	defer cmpSlotsLock.Unlock()
+	cmpSlots[slot].key.Store(nil)
+	cmpSlots[slot].data.Store(nil)
+	cmpSlotsUsed[slot] = false
+}
@@ -0,0 +1,382 @@
+package mdbx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// cmpByLen orders keys by length first.
+func cmpByLen(a, b []byte) int {
+	if c := cmpInt(len(a), len(b)); c != 0 {
+		return c
+	}
+	return bytes.Compare(a, b)
+}
+
+func TestTxn_OpenDBIWithCompare(t *testing.T) {
+	env, _ := setup(t)
+	keys := []string{"ccc", "a", "bb", "b", "aaaa", "ab"}
+	var db DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		db, err = txn.OpenDBIWithCompare("bylen", Create|DupSort, cmpByLen, CmpDescending)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			for _, v := range []string{"1", "3", "2"} {
+				if err := txn.Put(db, []byte(k), []byte(v), 0); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		// reopening with other Go comparators keeps the first ones.
+		db2, err := txn.OpenDBIWithCompare("bylen", DupSort, bytes.Compare, nil)
+		if err != nil || db2 != db {
+			t.Errorf("unexpected result of a reopen: %d %v", db2, err)
+		}
+		if c := txn.Cmp(db, []byte("b"), []byte("aa")); c != -1 {
+			t.Errorf("unexpected Cmp result %d", c)
+		}
+		if c := txn.DCmp(db, []byte("1"), []byte("2")); c != 1 {
+			t.Errorf("unexpected DCmp result %d", c)
+		}
+
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		var got []string
+		for op := uint(First); ; op = Next {
+			k, v, err := cur.Get(nil, nil, op)
+			if IsNotFound(err) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			got = append(got, string(k)+"="+string(v))
+		}
+		expect := []string{
+			"a=3", "a=2", "a=1", "b=3", "b=2", "b=1",
+			"ab=3", "ab=2", "ab=1", "bb=3", "bb=2", "bb=1",
+			"ccc=3", "ccc=2", "ccc=1", "aaaa=3", "aaaa=2", "aaaa=1",
+		}
+		if !slices.Equal(got, expect) {
+			t.Errorf("unexpected order %q", got)
+		}
+		if _, err = txn.OpenDBIWithCompare("bylen", DupSort, CmpUint64BE, nil); !IsErrnoSys(err, syscall.EINVAL) {
+			t.Errorf("unexpected error for another C comparator: %v", err)
+		}
+		// and the handle stays open.
+		if _, err = txn.Get(db, []byte("ab")); err != nil {
+			t.Errorf("unexpected error after a failed reopen: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_OpenDBIWithCompare_builtins(t *testing.T) {
+	env, _ := setup(t)
+	rng := rand.New(rand.NewSource(1))
+	randKey := func() []byte {
+		k := make([]byte, rng.Intn(12))
+		rng.Read(k)
+		for i := range k {
+			// small bytes make for leading zeros and valid prefixes.
+			if rng.Intn(2) == 0 {
+				k[i] %= 4
+			}
+		}
+		return k
+	}
+
+	for _, test := range []struct {
+		name string
+		cmp  func(a, b []byte) int
+	}{
+		{"uint64be", CmpUint64BE},
+		{"descending", CmpDescending},
+		{"lenprefixed", CmpLengthPrefixed},
+	} {
+		keys := [][]byte{{}, {0}, {0, 0}, {1}, {0, 1}, {1, 0}, {2, 'a', 'b'}, {1, 'b'}, {2, 'a'}, {0x80}, {0xff, 0xff}}
+		for range 500 {
+			keys = append(keys, randKey())
+		}
+		var db DBI
+		err := env.Update(func(txn *Txn) (err error) {
+			db, err = txn.OpenDBIWithCompare(test.name, Create, test.cmp, nil)
+			if err != nil {
+				return err
+			}
+			for _, k := range keys {
+				if err := txn.Put(db, k, nil, 0); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		// distinct keys never compare equal.
+		slices.SortFunc(keys, test.cmp)
+		keys = slices.CompactFunc(keys, func(a, b []byte) bool { return test.cmp(a, b) == 0 })
+
+		err = env.View(func(txn *Txn) error {
+			// the C comparator agrees with the Go one.
+			for range 1000 {
+				a, b := keys[rng.Intn(len(keys))], keys[rng.Intn(len(keys))]
+				if c, expect := txn.Cmp(db, a, b), test.cmp(a, b); c != expect {
+					t.Errorf("%s: compare %x to %x: %d, expected %d", test.name, a, b, c, expect)
+				}
+			}
+			cur, err := txn.OpenCursor(db)
+			if err != nil {
+				return err
+			}
+			defer cur.Close()
+			for i, op := 0, uint(First); ; i, op = i+1, Next {
+				k, _, err := cur.Get(nil, nil, op)
+				if IsNotFound(err) {
+					if i != len(keys) {
+						t.Errorf("%s: %d keys, expected %d", test.name, i, len(keys))
+					}
+					break
+				}
+				if err != nil {
+					return err
+				}
+				if i >= len(keys) || !bytes.Equal(k, keys[i]) {
+					t.Errorf("%s: key %d is %x", test.name, i, k)
+					break
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+	}
+}
+
+func TestCmpUint64BE(t *testing.T) {
+	for _, test := range []struct {
+		a, b   []byte
+		expect int
+	}{
+		{binary.BigEndian.AppendUint64(nil, 255), binary.BigEndian.AppendUint64(nil, 256), -1},
+		{[]byte{1}, binary.BigEndian.AppendUint64(nil, 1), -1},
+		{[]byte{0, 2}, []byte{1}, 1},
+		{[]byte{1, 0}, []byte{0xff}, 1},
+		{[]byte{}, []byte{0}, -1},
+		{[]byte{0, 1, 2, 3, 4, 5, 6, 7, 8}, []byte{1, 2, 3, 4, 5, 6, 7, 8}, 1},
+	} {
+		if c := CmpUint64BE(test.a, test.b); c != test.expect {
+			t.Errorf("compare %x to %x: %d", test.a, test.b, c)
+		}
+		if c := CmpUint64BE(test.b, test.a); c != -test.expect {
+			t.Errorf("compare %x to %x: %d", test.b, test.a, c)
+		}
+	}
+}
+
+func TestCmpLengthPrefixed(t *testing.T) {
+	key := func(field, suffix string) []byte {
+		return append(binary.AppendUvarint(nil, uint64(len(field))), field+suffix...)
+	}
+	for _, test := range []struct {
+		a, b   []byte
+		expect int
+	}{
+		{key("b", ""), key("ab", ""), 1},
+		{key("ab", "z"), key("abc", "a"), -1},
+		{key("ab", "a"), key("ab", "b"), -1},
+		{key("ab", ""), key("ab", ""), 0},
+		// a malformed key is all field, and differs from a valid one.
+		{[]byte{5, 'a'}, key("a", ""), -1},
+		{[]byte{5, 'a'}, key("\x05a", ""), 1},
+	} {
+		if c := CmpLengthPrefixed(test.a, test.b); c != test.expect {
+			t.Errorf("compare %x to %x: %d", test.a, test.b, c)
+		}
+		if c := CmpLengthPrefixed(test.b, test.a); c != -test.expect {
+			t.Errorf("compare %x to %x: %d", test.b, test.a, c)
+		}
+	}
+}
+
+func TestTxn_OpenDBIWithCompare_slots(t *testing.T) {
+	env, _ := setup(t)
+	var free int
+	for i := range cmpSlotsUsed {
+		if !cmpSlotsUsed[i] {
+			free++
+		}
+	}
+	var dbis []DBI
+	err := env.Update(func(txn *Txn) error {
+		for i := range free {
+			dbi, err := txn.OpenDBIWithCompare(string(rune('a'+i%26))+string(rune('0'+i/26)), Create, cmpByLen, nil)
+			if err != nil {
+				return err
+			}
+			dbis = append(dbis, dbi)
+		}
+		// tables with C comparators need no slot.
+		if _, err := txn.OpenDBIWithCompare("c", Create, CmpDescending, nil); err != nil {
+			return err
+		}
+		if _, err := txn.OpenDBIWithCompare("full", Create, cmpByLen, nil); !IsErrno(err, DBsFull) {
+			t.Errorf("unexpected error with the slots taken: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env.CloseDBI(dbis[0])
+	err = env.Update(func(txn *Txn) error {
+		_, err := txn.OpenDBIWithCompare("full", Create, cmpByLen, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error with a slot released: %v", err)
+	}
+	env.Close()
+	for i := range cmpSlotsUsed {
+		if cmpSlotsUsed[i] {
+			t.Fatalf("slot %d is still used", i)
+		}
+	}
+}
+
+func TestTxn_OpenDBIWithCompare_panic(t *testing.T) {
+	env, _ := setup(t)
+	cmp := func(a, b []byte) int {
+		if string(a) == "boom" || string(b) == "boom" {
+			panic("boom")
+		}
+		return bytes.Compare(a, b)
+	}
+	var db DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		db, err = txn.OpenDBIWithCompare("panic", Create, cmp, nil)
+		if err != nil {
+			return err
+		}
+		for _, k := range []string{"a", "b", "c"} {
+			if err := txn.Put(db, []byte(k), nil, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.Update(func(txn *Txn) error {
+		return txn.Put(db, []byte("boom"), nil, 0)
+	})
+	if err == nil || !strings.Contains(err.Error(), "panic in comparator: boom") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = env.View(func(txn *Txn) error {
+		_, err := txn.Get(db, []byte("a"))
+		return err
+	})
+	if err == nil || !strings.Contains(err.Error(), "panic in comparator: boom") {
+		t.Errorf("unexpected error of a later txn: %v", err)
+	}
+}
+
+func TestTxn_OpenDBIWithCompare_rename(t *testing.T) {
+	env, _ := setup(t)
+	err := env.Update(func(txn *Txn) error {
+		db, err := txn.OpenDBIWithCompare("old", Create, CmpDescending, nil)
+		if err != nil {
+			return err
+		}
+		return txn.RenameDBI(db, "new")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.Update(func(txn *Txn) error {
+		// the comparators moved along with the table.
+		if _, err := txn.OpenDBIWithCompare("new", 0, CmpUint64BE, nil); !IsErrnoSys(err, syscall.EINVAL) {
+			t.Errorf("unexpected error for other comparators of the new name: %v", err)
+		}
+		_, err := txn.OpenDBIWithCompare("old", Create, CmpUint64BE, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error for a table of the old name: %v", err)
+	}
+}
+
+func BenchmarkTxn_OpenDBIWithCompare(b *testing.B) {
+	env, _ := setup(b)
+	const n = 100000
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = binary.BigEndian.AppendUint64(nil, uint64(i)*7919)
+	}
+	for _, bench := range []struct {
+		name string
+		cmp  func(a, b []byte) int
+	}{
+		{"builtin", nil},
+		{"C", CmpUint64BE},
+		{"Go", func(a, b []byte) int { return bytes.Compare(a, b) }},
+	} {
+		var db DBI
+		err := env.Update(func(txn *Txn) (err error) {
+			db, err = txn.OpenDBIWithCompare(bench.name, Create, bench.cmp, nil)
+			if err != nil {
+				return err
+			}
+			for _, k := range keys {
+				if err := txn.Put(db, k, k, 0); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(bench.name, func(b *testing.B) {
+			err := env.View(func(txn *Txn) error {
+				i := 0
+				for b.Loop() {
+					if _, err := txn.Get(db, keys[i*4099%n]); err != nil {
+						return err
+					}
+					i++
+				}
+				return nil
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package mdbx
+
+/*
+#include <stdlib.h>
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"io"
+	"os"
+	"unsafe"
+)
+
+// Copy copies the data in env to a new database file at path.  No lock file
+// is created for the copy; libmdbx recreates it when the copy is opened, which
+// must be done with the NoSubdir flag.
+//
+// flags is a combination of CopyCompact, CopyForceDynamicSize, CopyDontFlush,
+// CopyThrottleMVCC and CopyOverwrite.  CopyDisposeTxn and CopyRenewTxn are
+// only meaningful for Txn copies and are rejected with EINVAL.
+//
+// The copy is taken from a read transaction, so it can cause significant file
+// growth when run in parallel with write transactions.
+//
+// See mdbx_env_copy.
+func (env *Env) Copy(path string, flags uint) error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+	ret := C.mdbx_env_copy(env._env, cpath, C.MDBX_copy_flags_t(flags))
+	return operrno("mdbx_env_copy", ret)
+}
+
+// CopyToFD copies env to the file descriptor (or Windows file handle) fd,
+// which must be open for writing.  See Copy for the accepted flags.
+//
+// fd may be a pipe, socket or FIFO, unless the environment has suffered a
+// page leak, in which case libmdbx needs to seek and the copy fails.
+//
+// See mdbx_env_copy2fd.
+func (env *Env) CopyToFD(fd uintptr, flags uint) error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
+	ret := C.mdbxgo_env_copy2fd(env._env, C.uintptr_t(fd), C.MDBX_copy_flags_t(flags))
+	return operrno("mdbx_env_copy2fd", ret)
+}
+
+// CopyTo streams a copy of env into w.  The data is written by libmdbx into a
+// pipe whose other end is pumped into w, so w receives exactly the bytes of a
+// database file that may be opened with the NoSubdir flag.  See Copy for the
+// accepted flags.
+//
+// An error returned by w aborts the copy and is returned in preference to
+// the error reported by libmdbx.
+//
+// See mdbx_env_copy2fd.
+func (env *Env) CopyTo(w io.Writer, flags uint) error {
+	return copyToWriter(w, func(fd uintptr) error {
+		return env.CopyToFD(fd, flags)
+	})
+}
+
+// CopyToPath copies the MVCC snapshot of txn to a new database file at path.
+// Unlike Env.Copy, the copy reflects exactly the data visible to txn, so a
+// backup can be pinned to a known transaction ID.
+//
+// Besides the flags accepted by Env.Copy, flags may include CopyRenewTxn,
+// which restarts txn on the most recent snapshot when its own is outdated
+// instead of failing, and CopyDisposeTxn, which aborts txn once the copy is
+// done.  A disposed Txn must not be used again; CopyDisposeTxn panics for a
+// managed Txn since View and Update terminate those themselves.
+//
+// See mdbx_txn_copy2pathname.
+func (txn *Txn) CopyToPath(path string, flags uint) error {
+	txn.checkCopyFlags(flags)
+	if err := txn.env.checkFork(); err != nil {
+		return err
+	}
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+	ret := C.mdbx_txn_copy2pathname(txn._txn, cpath, C.MDBX_copy_flags_t(flags))
+	txn.afterCopy(flags)
+	return txn.operrno("mdbx_txn_copy2pathname", ret)
+}
+
+// CopyToFD copies the MVCC snapshot of txn to the file descriptor (or Windows
+// file handle) fd, which must be open for writing.  See CopyToPath for the
+// accepted flags.
+//
+// See mdbx_txn_copy2fd.
+func (txn *Txn) CopyToFD(fd uintptr, flags uint) error {
+	txn.checkCopyFlags(flags)
+	if err := txn.env.checkFork(); err != nil {
+		return err
+	}
+	ret := C.mdbxgo_txn_copy2fd(txn._txn, C.uintptr_t(fd), C.MDBX_copy_flags_t(flags))
+	txn.afterCopy(flags)
+	return txn.operrno("mdbx_txn_copy2fd", ret)
+}
+
+// CopyTo streams a copy of the MVCC snapshot of txn into w, pumping the data
+// through a pipe like Env.CopyTo.  See CopyToPath for the accepted flags.
+//
+// See mdbx_txn_copy2fd.
+func (txn *Txn) CopyTo(w io.Writer, flags uint) error {
+	return copyToWriter(w, func(fd uintptr) error {
+		return txn.CopyToFD(fd, flags)
+	})
+}
+
+func (txn *Txn) checkCopyFlags(flags uint) {
+	if flags&CopyDisposeTxn != 0 && txn.managed {
+		panic("managed transaction cannot be disposed by a copy")
+	}
+}
+
+// afterCopy mirrors on the Go side what libmdbx did to the C handle: with
+// CopyDisposeTxn it was aborted whatever the outcome, and with CopyRenewTxn
+// it may have been restarted on a newer snapshot.
+func (txn *Txn) afterCopy(flags uint) {
+	switch {
+	case flags&CopyDisposeTxn != 0:
+		txn.clearTxn()
+	case flags&CopyRenewTxn != 0:
+		txn.resetID()
+	}
+}
+
+// copyToWriter runs copyFD against the write end of a pipe while the read end
+// is pumped into w.
+func copyToWriter(w io.Writer, copyFD func(fd uintptr) error) error {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	pumped := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(w, pr)
+		// Closing the read end makes further writes by libmdbx fail with
+		// EPIPE, so a failing w aborts the copy rather than stalling it.
+		pr.Close()
+		pumped <- err
+	}()
+
+	err = copyFD(pw.Fd())
+	// Closing the write end delivers EOF to the pump once it has drained the
+	// pipe.
+	pw.Close()
+	if werr := <-pumped; werr != nil {
+		return werr
+	}
+	return err
+}
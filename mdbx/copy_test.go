@@ -0,0 +1,165 @@
+package mdbx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnv_Copy(t *testing.T) {
+	env, dbi := setupReaderInfoEnv(t)
+
+	for _, flags := range []uint{CopyDefaults, CopyCompact} {
+		dest := filepath.Join(t.TempDir(), "copy.mdbx")
+		if err := env.Copy(dest, flags); err != nil {
+			t.Fatalf("copy (flags %#x): %v", flags, err)
+		}
+		checkCopyEntries(t, dest, dbi, 2048)
+
+		// the destination must not be overwritten unless asked to.
+		if err := env.Copy(dest, flags); err == nil {
+			t.Fatalf("copy (flags %#x) over an existing file succeeded", flags)
+		}
+		if err := env.Copy(dest, flags|CopyOverwrite); err != nil {
+			t.Fatalf("copy (flags %#x) with CopyOverwrite: %v", flags, err)
+		}
+	}
+}
+
+func TestEnv_Copy_txnFlags(t *testing.T) {
+	env, _ := setup(t)
+
+	dest := filepath.Join(t.TempDir(), "copy.mdbx")
+	err := env.Copy(dest, CopyRenewTxn)
+	if err == nil {
+		t.Fatalf("copy with a Txn-only flag succeeded")
+	}
+}
+
+func TestEnv_CopyTo(t *testing.T) {
+	env, dbi := setupReaderInfoEnv(t)
+
+	var buf bytes.Buffer
+	if err := env.CopyTo(&buf, CopyCompact); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	dest := filepath.Join(t.TempDir(), "copy.mdbx")
+	if err := os.WriteFile(dest, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	checkCopyEntries(t, dest, dbi, 2048)
+}
+
+func TestEnv_CopyTo_writerError(t *testing.T) {
+	env, _ := setupReaderInfoEnv(t)
+
+	want := errors.New("archive unavailable")
+	err := env.CopyTo(failingWriter{want}, 0)
+	if !errors.Is(err, want) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTxn_CopyTo_snapshot(t *testing.T) {
+	env, dbi := setupReaderInfoEnv(t)
+
+	txn, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer txn.Abort()
+
+	// changes committed after txn began must not be part of its copy.
+	err = env.Update(func(txn *Txn) error {
+		for i := range 16 {
+			if err := txn.Put(dbi, fmt.Appendf(nil, "late-%04d", i), []byte("v"), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := txn.CopyTo(&buf, CopyCompact); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	dest := filepath.Join(t.TempDir(), "copy.mdbx")
+	if err := os.WriteFile(dest, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	checkCopyEntries(t, dest, dbi, 2048)
+
+	dest = filepath.Join(t.TempDir(), "copy.mdbx")
+	if err := txn.CopyToPath(dest, 0); err != nil {
+		t.Fatalf("copy to path: %v", err)
+	}
+	checkCopyEntries(t, dest, dbi, 2048)
+}
+
+func TestTxn_CopyToPath_dispose(t *testing.T) {
+	env, dbi := setupReaderInfoEnv(t)
+
+	txn, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer txn.Abort()
+
+	dest := filepath.Join(t.TempDir(), "copy.mdbx")
+	if err := txn.CopyToPath(dest, CopyDisposeTxn); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if txn._txn != nil {
+		t.Fatalf("txn handle retained after CopyDisposeTxn")
+	}
+	checkCopyEntries(t, dest, dbi, 2048)
+
+	err = env.View(func(txn *Txn) (err error) {
+		defer func() {
+			if recover() == nil {
+				err = errors.New("no panic disposing a managed txn")
+			}
+		}()
+		return txn.CopyToPath(filepath.Join(t.TempDir(), "copy.mdbx"), CopyDisposeTxn)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+
+// checkCopyEntries opens the copied database file at path and checks that dbi
+// holds n entries.
+func checkCopyEntries(t *testing.T, path string, dbi DBI, n uint64) {
+	t.Helper()
+	env, err := NewEnv(Default)
+	if err != nil {
+		t.Fatalf("env: %v", err)
+	}
+	defer env.Close()
+	if err = env.Open(path, NoSubdir|Readonly, 0644); err != nil {
+		t.Fatalf("open copy: %v", err)
+	}
+	err = env.View(func(txn *Txn) error {
+		stat, err := txn.StatDBI(dbi)
+		if err != nil {
+			return err
+		}
+		if stat.Entries != n {
+			return fmt.Errorf("copy has %d entries (!= %d)", stat.Entries, n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -102,6 +102,8 @@ type Cursor struct {
 	noCopy noCopy
 	txn    *Txn
 	_c     *C.MDBX_cursor
+	batch  []C.MDBX_val // scratch space of GetBatch
+	uctx   userctx      // see SetUserData
 }
 
 // Open binds an unopened Cursor to the table in place. Unlike Txn.OpenCursor it
@@ -121,7 +123,7 @@ func (c *Cursor) Open(txn *Txn, db DBI) error {
 	}
 	ret := C.mdbx_cursor_open(txn._txn, C.MDBX_dbi(db), &c._c)
 	if ret != success {
-		return operrno("mdbx_cursor_open", ret)
+		return txn.operrno("mdbx_cursor_open", ret)
 	}
 	c.txn = txn
 	return nil
@@ -146,7 +148,7 @@ func openCursor(txn *Txn, db DBI) (*Cursor, error) {
 func (c *Cursor) Renew(txn *Txn) error {
 	ret := C.mdbx_cursor_renew(txn._txn, c._c)
 	if ret != success {
-		return operrno("mdbx_cursor_renew", ret)
+		return txn.operrno("mdbx_cursor_renew", ret)
 	}
 	c.txn = txn
 	return nil
@@ -157,7 +159,7 @@ func (c *Cursor) Renew(txn *Txn) error {
 func (c *Cursor) Bind(txn *Txn, db DBI) error {
 	ret := C.mdbx_cursor_bind(txn._txn, c._c, C.MDBX_dbi(db))
 	if ret != success {
-		return operrno("mdbx_cursor_bind", ret)
+		return txn.operrno("mdbx_cursor_bind", ret)
 	}
 	c.txn = txn
 	return nil
@@ -170,7 +172,7 @@ func (c *Cursor) Bind(txn *Txn, db DBI) error {
 func (c *Cursor) Unbind() error {
 	ret := C.mdbx_cursor_unbind(c._c)
 	if ret != success {
-		return operrno("mdbx_cursor_unbind", ret)
+		return c.operrno("mdbx_cursor_unbind", ret)
 	}
 	c.txn = nil
 	return nil
@@ -188,12 +190,25 @@ func (c *Cursor) Close() {
 		return
 	}
 	if c._c != nil {
-		C.mdbx_cursor_close(c._c)
+		// a cursor inherited over a fork is leaked unless the Env is
+		// resurrected: libmdbx would abort the child on the failure.
+		if c.txn == nil || c.txn.env.checkFork() == nil {
+			C.mdbx_cursor_close(c._c)
+		}
 		c.txn = nil
 		c._c = nil
+		c.uctx.release()
 	}
 }
 
+// operrno is Txn.operrno for the operations on c.
+func (c *Cursor) operrno(op string, ret C.int) error {
+	if c.txn == nil {
+		return operrno(op, ret)
+	}
+	return c.txn.operrno(op, ret)
+}
+
 // Txn returns the cursor's transaction.
 func (c *Cursor) Txn() *Txn {
 	return c.txn
@@ -241,7 +256,7 @@ func (c *Cursor) Get(setkey, setval []byte, op uint) (key, val []byte, err error
 	// operrno, not a bare success check: mdbx_cursor_get returns
 	// MDBX_RESULT_TRUE (found a greater key/pair) for SetLowerBound and
 	// SetUpperBound, which is success-with-data, not an error.
-	if err := operrno("mdbx_cursor_get", r.err); err != nil {
+	if err := c.operrno("mdbx_cursor_get", r.err); err != nil {
 		return nil, nil, err
 	}
 
@@ -291,7 +306,7 @@ func (c *Cursor) getVal(setkey, setval []byte, op uint) C.mdbxgo_val_result {
 // See mdb_cursor_put.
 func (c *Cursor) Put(key, val []byte, flags uint) error {
 	if c._c == nil || c.txn == nil {
-		return operrno("mdbx_cursor_put", C.MDBX_EINVAL)
+		return c.operrno("mdbx_cursor_put", C.MDBX_EINVAL)
 	}
 	var k, v *C.char
 	if len(key) > 0 {
@@ -306,7 +321,7 @@ func (c *Cursor) Put(key, val []byte, flags uint) error {
 		v, C.size_t(len(val)),
 		C.MDBX_put_flags_t(flags),
 	)
-	return operrno("mdbx_cursor_put", ret)
+	return c.operrno("mdbx_cursor_put", ret)
 }
 
 // PutReserve returns a []byte of length n that can be written to, potentially
@@ -328,7 +343,7 @@ func (c *Cursor) PutReserve(key []byte, n int, flags uint) ([]byte, error) {
 		C.size_t(n),
 		C.MDBX_put_flags_t(flags),
 	)
-	if err := operrno("mdbx_cursor_put", r.err); err != nil {
+	if err := c.operrno("mdbx_cursor_put", r.err); err != nil {
 		return nil, err
 	}
 	return castToBytesRaw(unsafe.Pointer(r.vbase), r.vlen), nil
@@ -355,7 +370,7 @@ func (c *Cursor) PutMulti(key []byte, page []byte, stride int, flags uint) error
 		v, C.size_t(vn), C.size_t(stride),
 		C.MDBX_put_flags_t(flags|C.MDBX_MULTIPLE),
 	)
-	return operrno("mdbxgo_cursor_putmulti", ret)
+	return c.operrno("mdbxgo_cursor_putmulti", ret)
 }
 
 // PutCurrent replaces the data of the item at the current cursor position.
@@ -373,7 +388,7 @@ func (c *Cursor) PutCurrent(key, val []byte) error {
 // See mdb_cursor_del.
 func (c *Cursor) Del(flags uint) error {
 	ret := C.mdbx_cursor_del(c._c, C.MDBX_put_flags_t(flags))
-	return operrno("mdbx_cursor_del", ret)
+	return c.operrno("mdbx_cursor_del", ret)
 }
 
 // RangeDel deletes a range of items referred to by the cursor from the database.
@@ -382,7 +397,7 @@ func (c *Cursor) Del(flags uint) error {
 // Modes: see mdbx_cursor_bunch_delete.
 func (c *Cursor) RangeDel(mode uint) (numberAffected uint64, err error) {
 	r := C.mdbxgo_cursor_bunch_delete(c._c, C.MDBX_bunch_action_t(mode))
-	if err := operrno("mdbx_cursor_bunch_delete", r.err); err != nil {
+	if err := c.operrno("mdbx_cursor_bunch_delete", r.err); err != nil {
 		return 0, err
 	}
 	return uint64(r.val), nil
@@ -412,7 +427,7 @@ func cptr(c *Cursor) *C.MDBX_cursor {
 // See mdbx_cursor_delete_range.
 func (c *Cursor) DeleteRange(end *Cursor, endIncluding bool) (numberAffected uint64, err error) {
 	r := C.mdbxgo_cursor_delete_range(c._c, cptr(end), C.bool(endIncluding))
-	if err := operrno("mdbx_cursor_delete_range", r.err); err != nil {
+	if err := c.operrno("mdbx_cursor_delete_range", r.err); err != nil {
 		return 0, err
 	}
 	return uint64(r.val), nil
@@ -428,7 +443,7 @@ func (c *Cursor) DeleteRange(end *Cursor, endIncluding bool) (numberAffected uin
 // See mdbx_estimate_distance.
 func (c *Cursor) EstimateDistance(last *Cursor) (int, error) {
 	r := C.mdbxgo_estimate_distance(c._c, cptr(last))
-	if err := operrno("mdbx_estimate_distance", r.err); err != nil {
+	if err := c.operrno("mdbx_estimate_distance", r.err); err != nil {
 		return 0, err
 	}
 	return int(r.val), nil
@@ -457,7 +472,7 @@ func (c *Cursor) EstimateMove(key, data []byte, op uint) (int, error) {
 		d, C.size_t(len(data)),
 		C.MDBX_cursor_op(op),
 	)
-	if err := operrno("mdbx_estimate_move", r.err); err != nil {
+	if err := c.operrno("mdbx_estimate_move", r.err); err != nil {
 		return 0, err
 	}
 	return int(r.val), nil
@@ -477,7 +492,7 @@ func (c *Cursor) EstimateMove(key, data []byte, op uint) (int, error) {
 // See mdbx_cursor_distance.
 func (c *Cursor) Distance(last *Cursor, deepness uint) (int, error) {
 	r := C.mdbxgo_cursor_distance(c._c, cptr(last), C.unsigned(deepness))
-	if err := operrno("mdbx_cursor_distance", r.err); err != nil {
+	if err := c.operrno("mdbx_cursor_distance", r.err); err != nil {
 		return 0, err
 	}
 	return int(r.val), nil
@@ -498,7 +513,7 @@ func (c *Cursor) Distance(last *Cursor, deepness uint) (int, error) {
 // See mdbx_cursor_scroll.
 func (c *Cursor) Scroll(amount int, deepness uint) error {
 	ret := C.mdbx_cursor_scroll(c._c, C.intptr_t(amount), C.unsigned(deepness))
-	return operrno("mdbx_cursor_scroll", ret)
+	return c.operrno("mdbx_cursor_scroll", ret)
 }
 
 // DistributeCursors positions each cursor in cursors at an evenly-spaced
@@ -552,11 +567,104 @@ func DistributeCursors(first, last *Cursor, cursors []*Cursor, deepness uint) (a
 func (c *Cursor) Count() (uint64, error) {
 	r := C.mdbxgo_cursor_count(c._c)
 	if r.err != success {
-		return 0, operrno("mdbx_cursor_count", r.err)
+		return 0, c.operrno("mdbx_cursor_count", r.err)
 	}
 	return uint64(r.val), nil
 }
 
+// EOF reports whether c is not on a pair, because it was never positioned or
+// a seek such as SetLowerBound found nothing.  A closed or unbound cursor is
+// at EOF as well.  A Next or Prev that fails at either end of the table
+// leaves c on the last or first pair, so EOF is false then, unlike
+// mdbx_cursor_eof, which is true on the last pair once Last or a failed Next
+// got there.
+//
+// See mdbx_cursor_eof.
+func (c *Cursor) EOF() bool {
+	return !bool(C.mdbxgo_cursor_on(c._c, C.MDBXGO_ON_PAIR))
+}
+
+// OnFirst reports whether c is on the first key of the table.  A cursor at
+// EOF is on no key and so never on the first, unlike with
+// mdbx_cursor_on_first.  In DupSort tables OnFirst considers the key only,
+// see OnFirstDup.
+//
+// See mdbx_cursor_on_first.
+func (c *Cursor) OnFirst() bool {
+	return bool(C.mdbxgo_cursor_on(c._c, C.MDBXGO_ON_FIRST))
+}
+
+// OnLast reports whether c is on the last key of the table, see OnFirst.
+//
+// See mdbx_cursor_on_last.
+func (c *Cursor) OnLast() bool {
+	return bool(C.mdbxgo_cursor_on(c._c, C.MDBXGO_ON_LAST))
+}
+
+// OnFirstDup reports whether c is on the first value of its key.  It is true
+// whenever c is on a pair of a table without DupSort, as a single value is
+// both the first and the last.  A cursor at EOF is never on the first value.
+//
+// See mdbx_cursor_on_first_dup.
+func (c *Cursor) OnFirstDup() bool {
+	return bool(C.mdbxgo_cursor_on(c._c, C.MDBXGO_ON_FIRST_DUP))
+}
+
+// OnLastDup reports whether c is on the last value of its key, see
+// OnFirstDup.
+//
+// See mdbx_cursor_on_last_dup.
+func (c *Cursor) OnLastDup() bool {
+	return bool(C.mdbxgo_cursor_on(c._c, C.MDBXGO_ON_LAST_DUP))
+}
+
+// Compare compares the positions of c and other in the order of the table
+// and returns -1, 0 or +1 as c is before, at or after other.  In DupSort
+// tables ignoreDups compares the keys only, otherwise positions on the same
+// key are ordered by value.
+//
+// Both cursors must be on a pair, otherwise Compare fails with ErrNoData,
+// and bound to the same transaction and table, otherwise it fails with
+// EINVAL.
+//
+// See mdbx_cursor_compare.
+func (c *Cursor) Compare(other *Cursor, ignoreDups bool) (int, error) {
+	r := C.mdbxgo_cursor_compare(c._c, cptr(other), C.bool(ignoreDups))
+	if err := c.operrno("mdbx_cursor_compare", r.err); err != nil {
+		return 0, err
+	}
+	return int(r.val), nil
+}
+
+// Copy binds dst to the transaction and table of c and moves it to the
+// position of c, so that both can then move independently.  dst may be
+// bound elsewhere or unbound, e.g. from CreateCursor or CursorFromPool.  If c
+// is at EOF dst ends up there too.
+//
+// See mdbx_cursor_copy.
+func (c *Cursor) Copy(dst *Cursor) error {
+	if dst == nil || dst._c == nil {
+		return errors.New("mdbx.Cursor.Copy: nil or closed destination cursor")
+	}
+	ret := C.mdbx_cursor_copy(c._c, dst._c)
+	if ret != success {
+		return c.operrno("mdbx_cursor_copy", ret)
+	}
+	dst.txn = c.txn
+	return nil
+}
+
+// Clone returns a new cursor at the position of c, see Copy.  It must be
+// closed like any other cursor.
+func (c *Cursor) Clone() (*Cursor, error) {
+	dst := CreateCursor()
+	if err := c.Copy(dst); err != nil {
+		dst.Close()
+		return nil, err
+	}
+	return dst, nil
+}
+
 var cursorPool = sync.Pool{
 	New: func() any {
 		return CreateCursor()
@@ -585,6 +693,11 @@ func CursorToPool(c *Cursor) {
 		c.Close()
 		return
 	}
+	// The user data is dropped too, so it does not pass to the next user.
+	if c.uctx != 0 && c.SetUserData(nil) != nil {
+		c.Close()
+		return
+	}
 	cursorPool.Put(c)
 }
 
@@ -11,6 +11,7 @@ import (
 	"os"
 	"reflect"
 	"runtime"
+	"syscall"
 	"testing"
 )
 
@@ -2508,3 +2509,252 @@ func TestCursor_Get_SetLowerBound_Inexact(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestCursor_OnFirstLast(t *testing.T) {
+	env, _ := setup(t)
+	db := mustPutSeqBE(t, env, "seq", 10)
+	dups := mustOpenDupSortDB(t, env, "dups")
+	if err := env.Update(func(txn *Txn) error {
+		for _, v := range []string{"1", "2", "3"} {
+			if err := txn.Put(dups, []byte("k"), []byte(v), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	type pos struct{ eof, first, last, firstDup, lastDup bool }
+	at := func(cur *Cursor) pos {
+		return pos{cur.EOF(), cur.OnFirst(), cur.OnLast(), cur.OnFirstDup(), cur.OnLastDup()}
+	}
+
+	err := env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		// an unpositioned cursor is at no boundary.
+		if p := at(cur); p != (pos{eof: true}) {
+			t.Errorf("unpositioned: %+v", p)
+		}
+		for _, test := range []struct {
+			op     uint
+			expect pos
+		}{
+			{First, pos{first: true, firstDup: true, lastDup: true}},
+			{Next, pos{firstDup: true, lastDup: true}},
+			{Last, pos{last: true, firstDup: true, lastDup: true}},
+		} {
+			if _, _, err = cur.Get(nil, nil, test.op); err != nil {
+				return err
+			}
+			if p := at(cur); p != test.expect {
+				t.Errorf("op %d: %+v", test.op, p)
+			}
+		}
+		// a failed Next leaves the cursor on the last pair.
+		if _, _, err = cur.Get(nil, nil, Next); !IsNotFound(err) {
+			t.Errorf("unexpected error past the end: %v", err)
+		}
+		if p := at(cur); p != (pos{last: true, firstDup: true, lastDup: true}) {
+			t.Errorf("past the end: %+v", p)
+		}
+		if _, _, err = cur.Get(beKey(10), nil, SetLowerBound); !IsNotFound(err) {
+			t.Errorf("unexpected error of a seek past the end: %v", err)
+		}
+		if p := at(cur); p != (pos{eof: true}) {
+			t.Errorf("seek past the end: %+v", p)
+		}
+
+		dcur, err := txn.OpenCursor(dups)
+		if err != nil {
+			return err
+		}
+		defer dcur.Close()
+		for _, test := range []struct {
+			op     uint
+			expect pos
+		}{
+			{First, pos{first: true, last: true, firstDup: true}},
+			{NextDup, pos{first: true, last: true}},
+			{LastDup, pos{first: true, last: true, lastDup: true}},
+		} {
+			if _, _, err = dcur.Get(nil, nil, test.op); err != nil {
+				return err
+			}
+			if p := at(dcur); p != test.expect {
+				t.Errorf("dup op %d: %+v", test.op, p)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closed := CreateCursor()
+	closed.Close()
+	if p := at(closed); p != (pos{eof: true}) {
+		t.Errorf("closed: %+v", p)
+	}
+}
+
+func TestCursor_Compare(t *testing.T) {
+	env, _ := setup(t)
+	db := mustPutSeqBE(t, env, "seq", 10)
+	other := mustPutSeqBE(t, env, "other", 10)
+	dups := mustOpenDupSortDB(t, env, "dups")
+	if err := env.Update(func(txn *Txn) error {
+		for _, v := range []string{"1", "2"} {
+			if err := txn.Put(dups, []byte("k"), []byte(v), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := env.View(func(txn *Txn) error {
+		var curs [3]*Cursor
+		for i, dbi := range []DBI{db, db, other} {
+			cur, err := txn.OpenCursor(dbi)
+			if err != nil {
+				return err
+			}
+			defer cur.Close()
+			curs[i] = cur
+		}
+		a, b, c := curs[0], curs[1], curs[2]
+
+		if _, err := a.Compare(b, false); !errors.Is(err, ErrNoData) {
+			t.Errorf("unexpected error of unpositioned cursors: %v", err)
+		}
+		if _, _, err := a.Get(beKey(3), nil, SetKey); err != nil {
+			return err
+		}
+		if _, err := a.Compare(b, false); !errors.Is(err, ErrNoData) {
+			t.Errorf("unexpected error of an unpositioned cursor: %v", err)
+		}
+		if _, _, err := b.Get(beKey(5), nil, SetKey); err != nil {
+			return err
+		}
+		if cmp, err := a.Compare(b, false); err != nil || cmp != -1 {
+			t.Errorf("unexpected comparison: %d %v", cmp, err)
+		}
+		if cmp, err := b.Compare(a, false); err != nil || cmp != 1 {
+			t.Errorf("unexpected comparison: %d %v", cmp, err)
+		}
+		if _, _, err := b.Get(beKey(3), nil, SetKey); err != nil {
+			return err
+		}
+		if cmp, err := a.Compare(b, false); err != nil || cmp != 0 {
+			t.Errorf("unexpected comparison: %d %v", cmp, err)
+		}
+		if _, _, err := c.Get(beKey(3), nil, SetKey); err != nil {
+			return err
+		}
+		if _, err := a.Compare(c, false); !IsErrnoSys(err, syscall.EINVAL) {
+			t.Errorf("unexpected error of another table: %v", err)
+		}
+		if _, err := a.Compare(nil, false); err == nil {
+			t.Errorf("expected an error of a nil cursor")
+		}
+
+		d1, err := txn.OpenCursor(dups)
+		if err != nil {
+			return err
+		}
+		defer d1.Close()
+		d2, err := txn.OpenCursor(dups)
+		if err != nil {
+			return err
+		}
+		defer d2.Close()
+		if _, _, err = d1.Get(nil, nil, First); err != nil {
+			return err
+		}
+		if _, _, err = d2.Get(nil, nil, Last); err != nil {
+			return err
+		}
+		if cmp, err := d1.Compare(d2, false); err != nil || cmp != -1 {
+			t.Errorf("unexpected comparison of values: %d %v", cmp, err)
+		}
+		if cmp, err := d1.Compare(d2, true); err != nil || cmp != 0 {
+			t.Errorf("unexpected comparison of keys: %d %v", cmp, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCursor_Copy(t *testing.T) {
+	env, _ := setup(t)
+	db := mustPutSeqBE(t, env, "seq", 10)
+
+	err := env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		// the clone of an unpositioned cursor is unpositioned.
+		clone, err := cur.Clone()
+		if err != nil {
+			return err
+		}
+		if !clone.EOF() || clone.Txn() != txn || clone.DBI() != db {
+			t.Errorf("unexpected clone of an unpositioned cursor")
+		}
+		clone.Close()
+
+		if _, _, err = cur.Get(beKey(4), nil, SetKey); err != nil {
+			return err
+		}
+		clone, err = cur.Clone()
+		if err != nil {
+			return err
+		}
+		defer clone.Close()
+		if cmp, err := clone.Compare(cur, false); err != nil || cmp != 0 {
+			t.Errorf("unexpected comparison with the clone: %d %v", cmp, err)
+		}
+		// the cursors move independently.
+		if k, _, err := clone.Get(nil, nil, Next); err != nil || !bytes.Equal(k, beKey(5)) {
+			t.Errorf("clone moved to %x (%v)", k, err)
+		}
+		if k, _, err := cur.Get(nil, nil, GetCurrent); err != nil || !bytes.Equal(k, beKey(4)) {
+			t.Errorf("cursor moved to %x (%v)", k, err)
+		}
+
+		// Copy rebinds a pooled cursor.
+		pooled := CursorFromPool()
+		defer CursorToPool(pooled)
+		if err = clone.Copy(pooled); err != nil {
+			return err
+		}
+		if k, _, err := pooled.Get(nil, nil, GetCurrent); err != nil || !bytes.Equal(k, beKey(5)) || pooled.Txn() != txn {
+			t.Errorf("copy at %x (%v)", k, err)
+		}
+
+		closed := CreateCursor()
+		closed.Close()
+		if err = cur.Copy(closed); err == nil {
+			t.Errorf("expected an error of a closed destination")
+		}
+		if err = cur.Copy(cur); !IsErrnoSys(err, syscall.EINVAL) {
+			t.Errorf("unexpected error of copying onto itself: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
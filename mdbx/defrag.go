@@ -0,0 +1,158 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrDefragDiscontinue may be returned by a DefragOptions.Progress callback to
+// stop defragmentation gracefully: the page moves already scheduled for the
+// current cycle are completed and committed.  Env.Defrag does not report it
+// as an error.
+var ErrDefragDiscontinue = errors.New("mdbx: defragmentation discontinued")
+
+// Reasons reported in DefragResult.StoppingReasons.  Any number of them may
+// be OR'ed together.
+//
+// See MDBX_defrag_stopping_reasons_t.
+const (
+	DefragNoObstacles     = C.MDBX_defrag_noobstacles
+	DefragStepSize        = C.MDBX_defrag_step_size        // Step transaction size limit reached.
+	DefragLargeChunk      = C.MDBX_defrag_large_chunk      // Free space must be formed before a large page can move.
+	DefragDiscontinued    = C.MDBX_defrag_discontinued     // Discontinued by the progress callback.
+	DefragLaggardReader   = C.MDBX_defrag_laggard_reader   // A reader holds an old MVCC snapshot.
+	DefragEnoughThreshold = C.MDBX_defrag_enough_threshold // DefragOptions.Enough was reached.
+	DefragTimeLimit       = C.MDBX_defrag_time_limit       // DefragOptions.TimeLimit was reached.
+	DefragAborted         = C.MDBX_defrag_aborted          // Aborted by the progress callback or context.
+	DefragError           = C.MDBX_defrag_error            // An error occurred.
+)
+
+// DefragOptions controls the goals and limits of Env.Defrag.  The zero value
+// defragments as far as possible without time limits.
+//
+// Sizes are given in bytes and rounded up to whole pages of the environment.
+type DefragOptions struct {
+	// AtLeast is the size the database must shrink by before the goal is
+	// considered achieved.  Zero means no lower bound.
+	AtLeast uint64
+	// Enough is the size after which defragmentation stops rather than dig
+	// deeper.  It must not be less than AtLeast.  Zero means no limit.
+	Enough uint64
+	// MinTime is the wall-clock time to keep defragmenting even when the size
+	// goals have already been reached.  It must not exceed TimeLimit.
+	MinTime time.Duration
+	// TimeLimit bounds the wall-clock time spent; the page moves already
+	// being written when it expires are completed.  Zero means no limit.
+	TimeLimit time.Duration
+	// AcceptableBacklash stops defragmentation once a further cycle could not
+	// shrink the database by more than this size, so any size below a page
+	// stands for one page.  Zero lets libmdbx choose.
+	AcceptableBacklash uint64
+	// PreferredBatch is the preferred maximum number of pages moved per cycle.
+	// Zero means no limit.
+	PreferredBatch uint
+	// Progress, if not nil, is called at the start and end of every cycle and
+	// in between often enough to track progress.  Returning
+	// ErrDefragDiscontinue stops defragmentation gracefully, any other error
+	// aborts it and is returned by Env.Defrag.
+	Progress func(DefragResult) error
+}
+
+// DefragResult reports the progress and the outcome of Env.Defrag.
+//
+// See MDBX_defrag_result_t.
+type DefragResult struct {
+	PagesShrinked   int64  // Pages the database shrank by; may be negative.
+	PagesMoved      uint64 // Pages moved in total.
+	PagesScheduled  uint64 // Pages scheduled for the next stage of the cycle.
+	PagesRetained   uint64 // Pages retained by readers' MVCC snapshots.
+	PagesLeft       uint64 // Estimated pages that could still be defragmented.
+	PagesWhole      uint64 // Pages in the whole database.
+	ObstructedPgno  uint64 // Page where defragmentation stumbled.
+	ObstructedSpan  uint64 // Length of the large page span where it stumbled.
+	ObstructedTxnID uint64 // Oldest MVCC snapshot held by readers.
+	ObstructorTID   uint64 // Thread ID of a reader holding that snapshot.
+	ObstructorPID   int    // Process ID of a reader holding that snapshot.
+	CycleProgress   uint   // Rough progress of the current cycle in permille.
+	Cycles          uint   // Number of cycles performed.
+	StoppingReasons uint   // OR'ed Defrag* stopping reasons.
+	Elapsed         time.Duration
+}
+
+// Defrag moves data from pages at the end of the database to free pages
+// closer to the beginning and then cuts off the unused tail, honoring ACID:
+// every cycle ends with a commit, so an interrupted defragmentation keeps the
+// progress made so far.
+//
+// Defrag starts its own write transaction and so blocks while another write
+// transaction is running.  Readers do not prevent defragmentation but limit
+// it; when they keep it from completing Defrag returns an error for which
+// IsErrno(err, LaggardReader) is true.  If ctx is cancelled, defragmentation
+// is aborted at the next progress notification and ctx.Err() is returned.
+//
+// A nil error with non-zero StoppingReasons means the limits in opts stopped
+// defragmentation before its goals were fully achieved.
+//
+// See mdbx_env_defrag.
+func (env *Env) Defrag(ctx context.Context, opts DefragOptions) (DefragResult, error) {
+	if err := env.checkFork(); err != nil {
+		return DefragResult{}, err
+	}
+	info, err := env.Info(nil)
+	if err != nil {
+		return DefragResult{}, err
+	}
+	atleast, enough, backlash := opts.pages(uint64(info.PageSize))
+	dctx, done := newDefragFunc(ctx, opts.Progress)
+	defer done()
+
+	r := C.mdbxgo_env_defrag(env._env,
+		atleast, C.size_t(NewDuration16dot16(opts.MinTime)),
+		enough, C.size_t(NewDuration16dot16(opts.TimeLimit)),
+		backlash, C.size_t(opts.PreferredBatch),
+		C.size_t(dctx),
+	)
+	res := castDefragResult(&r.res)
+	if ctxerr := defragctxs.get(dctx).err; ctxerr != nil {
+		return res, ctxerr
+	}
+	return res, operrno("mdbx_env_defrag", r.err)
+}
+
+// pages converts the sizes of opts to whole pages of pageSize, rounding up.
+// A zero AcceptableBacklash becomes -1, which lets libmdbx choose.
+func (opts *DefragOptions) pages(pageSize uint64) (atleast, enough C.size_t, backlash C.intptr_t) {
+	atleast = C.size_t((opts.AtLeast + pageSize - 1) / pageSize)
+	enough = C.size_t((opts.Enough + pageSize - 1) / pageSize)
+	backlash = -1
+	if opts.AcceptableBacklash != 0 {
+		backlash = C.intptr_t((opts.AcceptableBacklash + pageSize - 1) / pageSize)
+	}
+	return atleast, enough, backlash
+}
+
+func castDefragResult(r *C.MDBX_defrag_result_t) DefragResult {
+	return DefragResult{
+		PagesShrinked:   int64(r.pages_shrinked),
+		PagesMoved:      uint64(r.pages_moved),
+		PagesScheduled:  uint64(r.pages_scheduled),
+		PagesRetained:   uint64(r.pages_retained),
+		PagesLeft:       uint64(r.pages_left),
+		PagesWhole:      uint64(r.pages_whole),
+		ObstructedPgno:  uint64(r.obstructed_pgno),
+		ObstructedSpan:  uint64(r.obstructed_span),
+		ObstructedTxnID: uint64(r.obstructed_txnid),
+		ObstructorTID:   uint64(C.mdbxgo_tid_to_u64(r.obstructor_tid)),
+		ObstructorPID:   int(r.obstructor_pid),
+		CycleProgress:   uint(r.rough_estimation_cycle_progress_permille),
+		Cycles:          uint(r.cycles),
+		StoppingReasons: uint(r.stopping_reasons),
+		Elapsed:         Duration16dot16(r.spent_time_dot16).ToDuration(),
+	}
+}
@@ -0,0 +1,167 @@
+package mdbx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEnv_Defrag(t *testing.T) {
+	env, dbi := setupFragmentedEnv(t)
+
+	var notified int
+	res, err := env.Defrag(context.Background(), DefragOptions{
+		Progress: func(DefragResult) error {
+			notified++
+			return nil
+		},
+	})
+	if err != nil && !IsErrno(err, LaggardReader) {
+		t.Fatalf("defrag: %v", err)
+	}
+	if notified == 0 {
+		t.Errorf("progress callback was never called")
+	}
+	if res.PagesMoved == 0 || res.PagesShrinked <= 0 {
+		t.Errorf("nothing defragmented: %+v", res)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		stat, err := txn.StatDBI(dbi)
+		if err != nil {
+			return err
+		}
+		if stat.Entries != 256 {
+			return fmt.Errorf("%d entries after defrag (!= 256)", stat.Entries)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnv_Defrag_subPageBacklash(t *testing.T) {
+	env, _ := setupFragmentedEnv(t)
+	opts := DefragOptions{AtLeast: 1, Enough: 4097, AcceptableBacklash: 1}
+	atleast, enough, backlash := opts.pages(4096)
+	if atleast != 1 || enough != 2 || backlash != 1 {
+		t.Errorf("unexpected pages %d %d %d", atleast, enough, backlash)
+	}
+	opts.AcceptableBacklash = 0
+	if _, _, backlash = opts.pages(4096); backlash != -1 {
+		t.Errorf("unexpected pages of a zero backlash %d", backlash)
+	}
+
+	res, err := env.Defrag(context.Background(), DefragOptions{AcceptableBacklash: 1})
+	if err != nil && !IsErrno(err, LaggardReader) {
+		t.Fatalf("defrag: %v", err)
+	}
+	if res.PagesMoved == 0 {
+		t.Errorf("nothing defragmented: %+v", res)
+	}
+}
+
+func TestEnv_Defrag_invalidLimits(t *testing.T) {
+	env, _ := setup(t)
+
+	_, err := env.Defrag(context.Background(), DefragOptions{AtLeast: 1 << 20, Enough: 4096})
+	if err == nil {
+		t.Fatalf("Enough < AtLeast accepted")
+	}
+}
+
+func TestEnv_Defrag_cancel(t *testing.T) {
+	env, _ := setupFragmentedEnv(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := env.Defrag(ctx, DefragOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.StoppingReasons&DefragAborted == 0 {
+		t.Errorf("aborted reason not reported: %+v", res)
+	}
+}
+
+func TestEnv_Defrag_discontinue(t *testing.T) {
+	env, _ := setupFragmentedEnv(t)
+
+	res, err := env.Defrag(context.Background(), DefragOptions{
+		Progress: func(DefragResult) error {
+			return ErrDefragDiscontinue
+		},
+	})
+	if err != nil {
+		t.Fatalf("defrag: %v", err)
+	}
+	if res.StoppingReasons&DefragDiscontinued == 0 {
+		t.Errorf("discontinued reason not reported: %+v", res)
+	}
+}
+
+func TestEnv_Defrag_callbackError(t *testing.T) {
+	env, _ := setupFragmentedEnv(t)
+
+	want := errors.New("stop defrag")
+	_, err := env.Defrag(context.Background(), DefragOptions{
+		Progress: func(DefragResult) error {
+			return want
+		},
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = env.Defrag(context.Background(), DefragOptions{
+		Progress: func(DefragResult) error {
+			panic("stop defrag")
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "stop defrag") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// setupFragmentedEnv fills a table and then deletes all but its last entries,
+// which leaves their pages at the end of the file.
+func setupFragmentedEnv(t *testing.T) (*Env, DBI) {
+	t.Helper()
+	env, _ := setup(t)
+	var dbi DBI
+	value := make([]byte, 1024)
+	err := env.Update(func(txn *Txn) (err error) {
+		dbi, err = txn.OpenRoot(0)
+		if err != nil {
+			return err
+		}
+		for i := range 4096 {
+			if err = txn.Put(dbi, fmt.Appendf(nil, "key-%05d", i), value, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+	err = env.Update(func(txn *Txn) error {
+		for i := range 4096 - 256 {
+			if err := txn.Del(dbi, fmt.Appendf(nil, "key-%05d", i), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	// an empty commit lets the pages freed above be reclaimed.
+	if err = env.Update(func(*Txn) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	return env, dbi
+}
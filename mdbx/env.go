@@ -10,7 +10,9 @@ import (
 	"errors"
 	"os"
 	"runtime"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
@@ -57,14 +59,20 @@ const (
 	MaxDbi      = C.MDBX_MAX_DBI
 )
 
-// These flags are exclusively used in the Env.CopyFlags and Env.CopyFDFlags
-// methods.
+// These flags are exclusively used by the Env.Copy* and Txn.Copy* methods.
 const (
-	// Flags for Env.CopyFlags
+	// Flags for Env.Copy and Txn.CopyToPath
 	//
-	// See mdbx_env_copy2
-
-	CopyCompact = C.MDBX_CP_COMPACT // Perform compaction while copying
+	// See MDBX_copy_flags_t
+
+	CopyDefaults         = C.MDBX_CP_DEFAULTS
+	CopyCompact          = C.MDBX_CP_COMPACT            // Perform compaction while copying
+	CopyForceDynamicSize = C.MDBX_CP_FORCE_DYNAMIC_SIZE // Make a resizable copy, i.e. dynamic size instead of fixed.
+	CopyDontFlush        = C.MDBX_CP_DONT_FLUSH         // Don't explicitly flush the written data to the output media.
+	CopyThrottleMVCC     = C.MDBX_CP_THROTTLE_MVCC      // Park the read txn while copying so writers may oust it.
+	CopyDisposeTxn       = C.MDBX_CP_DISPOSE_TXN        // Abort the passed txn after the copy (Txn only).
+	CopyRenewTxn         = C.MDBX_CP_RENEW_TXN          // Restart a txn with an outdated snapshot instead of failing (Txn only).
+	CopyOverwrite        = C.MDBX_CP_OVERWRITE          // Silently overwrite the destination file if it exists.
 )
 
 const (
@@ -156,6 +164,15 @@ type Env struct {
 	closeLock sync.RWMutex
 
 	strictThreadCheck bool
+
+	// forks is mdbxgo_forks as of NewEnv or Open, see checkFork.
+	forks C.unsigned
+
+	cmps dbiComparators
+
+	uctx userctx // see SetUserData
+
+	hsr atomic.Pointer[hsrfunc] // see SetHSR
 }
 
 // NewEnv allocates and initializes a new Env.
@@ -164,11 +181,18 @@ type Env struct {
 //
 //nolint:gocritic // false positive on dupSubExpr
 func NewEnv(label Label) (*Env, error) {
-	env := &Env{label: label}
+	env := &Env{label: label, forks: C.mdbxgo_forks}
 	ret := C.mdbx_env_create(&env._env)
 	if ret != success {
 		return nil, operrno("mdbx_env_create", ret)
 	}
+	env.uctx = userctxs.register(&_userctx{obj: env})
+	ret = C.mdbxgo_env_set_userctx(env._env, C.size_t(env.uctx))
+	if ret != success {
+		C.mdbx_env_close(env._env)
+		env.uctx.release()
+		return nil, operrno("mdbx_env_set_userctx", ret)
+	}
 	return env, nil
 }
 
@@ -188,9 +212,25 @@ func NewEnv(label Label) (*Env, error) {
 func (env *Env) Open(path string, flags uint, mode os.FileMode) error {
 	cpath := C.CString(path)
 	defer C.free(unsafe.Pointer(cpath))
+	env.forks = C.mdbxgo_forks
 	ret := C.mdbx_env_open(env._env, cpath, C.MDBX_env_flags_t(NoStickyThreads|flags), C.mdbx_mode_t(mode))
 	return operrno("mdbx_env_open", ret)
 }
+
+// checkFork returns ErrForked if env was inherited over a fork and not
+// resurrected since.  Every method that reaches libmdbx calls it first,
+// except Close and ResurrectAfterFork: libmdbx unmaps the database in the
+// child and, on the first env call there, marks the Env as failed for good,
+// so that even mdbx_env_resurrect_after_fork returns MDBX_PANIC.  Txn and
+// Cursor operations only fail with EPERM in that state and check it when
+// converting their errors instead, see Txn.operrno.
+func (env *Env) checkFork() error {
+	if env.forks != C.mdbxgo_forks {
+		return ErrForked
+	}
+	return nil
+}
+
 func (env *Env) Label() Label { return env.label }
 
 // SetStrictThreadMode in this mode mdbx panics when tx opening and closing are happening in different threads
@@ -205,6 +245,9 @@ var errNotOpen = errors.New("environment is not open")
 //
 // See mdbx_reader_check()
 func (env *Env) ReaderCheck() (int, error) {
+	if err := env.checkFork(); err != nil {
+		return 0, err
+	}
 	r := C.mdbxgo_reader_check(env._env)
 	err := operrno("mdbx_reader_check", r.err)
 	if err != nil {
@@ -229,49 +272,20 @@ func (env *Env) Close() error {
 		return nil
 	}
 
+	forked := env.checkFork() != nil
 	ret := C.mdbx_env_close(env._env)
+	if forked && ret == C.MDBX_PANIC {
+		// the way libmdbx reports releasing an env inherited over a fork.
+		ret = success
+	}
 	if ret != C.MDBX_BUSY {
+		env.cmps.release(0, true)
+		env.uctx.release()
 		env._env = nil
 	}
 	return operrno("mdbx_env_close", ret)
 }
 
-// CopyFD copies env to the file descriptor fd.
-//
-// See mdbx_env_copyfd.
-// func (env *Env) CopyFD(fd uintptr) error {
-//	ret := C.mdbx_env_copyfd(env._env, C.mdbx_filehandle_t(fd))
-//	return operrno("mdbx_env_copyfd", ret)
-//}
-
-// CopyFDFlag copies env to the file descriptor fd, with options.
-//
-// See mdbx_env_copyfd2.
-// func (env *Env) CopyFDFlag(fd uintptr, flags uint) error {
-//	ret := C.mdbx_env_copyfd2(env._env, C.mdbx_filehandle_t(fd), C.uint(flags))
-//	return operrno("mdbx_env_copyfd2", ret)
-//}
-
-// Copy copies the data in env to an environment at path.
-//
-// See mdbx_env_copy.
-// func (env *Env) Copy(path string) error {
-//	cpath := C.CString(path)
-//	defer C.free(unsafe.Pointer(cpath))
-//	ret := C.mdbx_env_copy(env._env, cpath)
-//	return operrno("mdbx_env_copy", ret)
-//}
-
-// CopyFlag copies the data in env to an environment at path created with flags.
-//
-// See mdbx_env_copy2.
-// func (env *Env) CopyFlag(path string, flags uint) error {
-//	cpath := C.CString(path)
-//	defer C.free(unsafe.Pointer(cpath))
-//	ret := C.mdbx_env_copy2(env._env, cpath, C.uint(flags))
-//	return operrno("mdbx_env_copy2", ret)
-//}
-
 // Stat contains database status information.
 //
 // See MDBX_stat.
@@ -289,21 +303,30 @@ type Stat struct {
 //
 // See mdbx_env_stat.
 func (env *Env) Stat() (*Stat, error) {
+	if err := env.checkFork(); err != nil {
+		return nil, err
+	}
 	var _stat C.MDBX_stat
 	var ret C.int = C.mdbx_env_stat_ex(env._env, nil, &_stat, C.size_t(unsafe.Sizeof(_stat)))
 	if ret != success {
 		return nil, operrno("mdbx_env_stat_ex", ret)
 	}
-	stat := Stat{PSize: uint(_stat.ms_psize),
-		Depth:         uint(_stat.ms_depth),
-		BranchPages:   uint64(_stat.ms_branch_pages),
-		LeafPages:     uint64(_stat.ms_leaf_pages),
-		OverflowPages: uint64(_stat.ms_overflow_pages),
-		Entries:       uint64(_stat.ms_entries),
-		LastTxId:      uint64(_stat.ms_mod_txnid)}
+	stat := castStat(&_stat)
 	return &stat, nil
 }
 
+func castStat(s *C.MDBX_stat) Stat {
+	return Stat{
+		PSize:         uint(s.ms_psize),
+		Depth:         uint(s.ms_depth),
+		BranchPages:   uint64(s.ms_branch_pages),
+		LeafPages:     uint64(s.ms_leaf_pages),
+		OverflowPages: uint64(s.ms_overflow_pages),
+		Entries:       uint64(s.ms_entries),
+		LastTxId:      uint64(s.ms_mod_txnid),
+	}
+}
+
 type EnvInfoGeo struct {
 	Lower   uint64
 	Upper   uint64
@@ -354,6 +377,68 @@ type EnvInfo struct {
 	AutosyncPeriod    time.Duration //
 	SinceReaderCheck  time.Duration //
 	Flags             uint          //
+
+	BootID [2]uint64   // ID of the current boot of the system, zeros if unavailable
+	Metas  [3]MetaInfo // States of the three meta pages
+}
+
+// MetaInfo describes one of the three meta pages, which reference the most
+// recently committed MVCC snapshots in rotation.
+//
+// See MDBX_envinfo.
+type MetaInfo struct {
+	TxnID  uint64    // ID of the transaction that wrote the meta page
+	Sign   uint64    // Data signature, see State
+	BootID [2]uint64 // ID of the system boot the meta page was written in
+}
+
+// MetaState tells how durable the snapshot referenced by a meta page is.
+type MetaState int
+
+// Meta page states reported by MetaInfo.State.
+const (
+	// MetaNoSync is a meta page committed without syncing, or one written by
+	// a legacy version of libmdbx.
+	MetaNoSync MetaState = iota
+	// MetaWeak is a meta page whose data was not synced to disk.  It is lost
+	// on the next open after a system crash, i.e. unless BootID matches the
+	// current boot.
+	MetaWeak
+	// MetaSteady is a meta page whose data was durably synced to disk.
+	MetaSteady
+)
+
+// Signatures of meta pages that are not steady, see DATASIGN_NONE and
+// DATASIGN_WEAK in libmdbx.
+const (
+	metaSignNone = 0
+	metaSignWeak = 1
+)
+
+// State returns how durable the snapshot referenced by the meta page is.
+func (m MetaInfo) State() MetaState {
+	switch m.Sign {
+	case metaSignNone:
+		return MetaNoSync
+	case metaSignWeak:
+		return MetaWeak
+	default:
+		return MetaSteady
+	}
+}
+
+// String implements fmt.Stringer.
+func (s MetaState) String() string {
+	switch s {
+	case MetaNoSync:
+		return "no-sync"
+	case MetaWeak:
+		return "weak"
+	case MetaSteady:
+		return "steady"
+	default:
+		return "MetaState(" + strconv.Itoa(int(s)) + ")"
+	}
 }
 
 // Info returns information about the environment.
@@ -371,6 +456,9 @@ func (env *Env) Info(txn *Txn) (*EnvInfo, error) {
 	if txn != nil {
 		ctxn = txn._txn
 	}
+	if err := env.checkFork(); err != nil {
+		return nil, err
+	}
 	var _info C.MDBX_envinfo
 	ret := C.mdbx_env_info_ex(env._env, ctxn, &_info, C.size_t(unsafe.Sizeof(_info)))
 	if ret != success {
@@ -392,6 +480,55 @@ func PreOpenSnapInfo(path string) (*EnvInfo, error) {
 	return castEnvInfo(_info), nil
 }
 
+// DeleteMode is how DeleteEnv deals with processes using the environment.
+//
+// See MDBX_env_delete_mode_t.
+type DeleteMode int
+
+// Modes of DeleteEnv.
+const (
+	// EnvJustDelete deletes the files whoever uses them.  On POSIX systems
+	// the processes with the environment open go on undisturbed until they
+	// close it.  On Windows the files of an open environment can't be
+	// deleted, and DeleteEnv fails with an *EnvInUseError.
+	EnvJustDelete DeleteMode = C.MDBX_ENV_JUST_DELETE
+	// EnvEnsureUnused fails with an *EnvInUseError if the environment is
+	// open, by this or another process.
+	EnvEnsureUnused DeleteMode = C.MDBX_ENV_ENSURE_UNUSED
+	// EnvWaitForUnused waits until the environment is closed everywhere.
+	EnvWaitForUnused DeleteMode = C.MDBX_ENV_WAIT_FOR_UNUSED
+)
+
+// EnvInUseError is returned by DeleteEnv for an environment that is open.
+type EnvInUseError struct {
+	Path string
+	Err  error // the *OpError of the failed lock
+}
+
+// Error implements the error interface.
+func (err *EnvInUseError) Error() string {
+	return "environment at " + err.Path + " is in use: " + err.Err.Error()
+}
+
+// Unwrap returns err.Err.
+func (err *EnvInUseError) Unwrap() error { return err.Err }
+
+// DeleteEnv deletes the files of the environment at path, and its directory
+// unless it was opened with NoSubdir, taking the locks of the environment as
+// mode says so as not to race with processes opening it.  An environment that
+// doesn't exist is not an error.
+//
+// See mdbx_env_delete.
+func DeleteEnv(path string, mode DeleteMode) error {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+	ret := C.mdbx_env_delete(cpath, C.MDBX_env_delete_mode_t(mode))
+	if isLockConflict(ret) {
+		return &EnvInUseError{Path: path, Err: operrno("mdbx_env_delete", ret)}
+	}
+	return operrno("mdbx_env_delete", ret)
+}
+
 func castEnvInfo(_info C.MDBX_envinfo) *EnvInfo {
 	return &EnvInfo{
 		MapSize: int64(_info.mi_mapsize),
@@ -432,6 +569,21 @@ func castEnvInfo(_info C.MDBX_envinfo) *EnvInfo {
 		AutosyncPeriod:    toDuration(_info.mi_autosync_period_seconds16dot16),
 		SinceReaderCheck:  toDuration(_info.mi_since_reader_check_seconds16dot16),
 		Flags:             uint(_info.mi_mode),
+
+		BootID: [2]uint64{uint64(_info.mi_bootid.current.x), uint64(_info.mi_bootid.current.y)},
+		Metas: [3]MetaInfo{
+			castMetaInfo(&_info, 0),
+			castMetaInfo(&_info, 1),
+			castMetaInfo(&_info, 2),
+		},
+	}
+}
+
+func castMetaInfo(_info *C.MDBX_envinfo, i int) MetaInfo {
+	return MetaInfo{
+		TxnID:  uint64(_info.mi_meta_txnid[i]),
+		Sign:   uint64(_info.mi_meta_sign[i]),
+		BootID: [2]uint64{uint64(_info.mi_bootid.meta[i].x), uint64(_info.mi_bootid.meta[i].y)},
 	}
 }
 
@@ -440,6 +592,9 @@ func castEnvInfo(_info C.MDBX_envinfo) *EnvInfo {
 //
 // See mdbx_env_sync.
 func (env *Env) Sync(force bool, nonblock bool) error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_env_sync_ex(env._env, C.bool(force), C.bool(nonblock))
 	return operrno("mdbx_env_sync_ex", ret)
 }
@@ -452,6 +607,9 @@ func (env *Env) Sync(force bool, nonblock bool) error {
 //
 // See mdbx_env_sync.
 func (env *Env) SyncForce() error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_env_sync(env._env)
 	return operrno("mdbx_env_sync", ret)
 }
@@ -468,6 +626,9 @@ func (env *Env) SyncForce() error {
 //
 // See mdbx_env_sync_poll.
 func (env *Env) SyncPoll() error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_env_sync_poll(env._env)
 	return operrno("mdbx_env_sync_poll", ret)
 }
@@ -476,6 +637,9 @@ func (env *Env) SyncPoll() error {
 //
 // See mdbx_env_set_flags.
 func (env *Env) SetFlags(flags uint) error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_env_set_flags(env._env, C.MDBX_env_flags_t(flags), true)
 	return operrno("mdbx_env_set_flags", ret)
 }
@@ -484,6 +648,9 @@ func (env *Env) SetFlags(flags uint) error {
 //
 // See mdbx_env_set_flags.
 func (env *Env) UnsetFlags(flags uint) error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_env_set_flags(env._env, C.MDBX_env_flags_t(flags), false)
 	return operrno("mdbx_env_set_flags", ret)
 }
@@ -492,6 +659,9 @@ func (env *Env) UnsetFlags(flags uint) error {
 //
 // See mdbx_env_get_flags.
 func (env *Env) Flags() (uint, error) {
+	if err := env.checkFork(); err != nil {
+		return 0, err
+	}
 	r := C.mdbxgo_env_get_flags(env._env)
 	if r.err != success {
 		return 0, operrno("mdbx_env_get_flags", r.err)
@@ -505,36 +675,57 @@ func (env *Env) SetDebug(logLvl LogLvl, dbg int, logger C.MDBX_debug_func) error
 }
 
 func (env *Env) SetOption(option uint, value uint64) error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_env_set_option(env._env, C.MDBX_option_t(option), C.uint64_t(value))
 	return operrno("mdbx_env_set_option", ret)
 }
 
 func (env *Env) GetOption(option uint) (uint64, error) {
+	if err := env.checkFork(); err != nil {
+		return 0, err
+	}
 	r := C.mdbxgo_env_get_option(env._env, C.MDBX_option_t(option))
 	return uint64(r.val), operrno("mdbx_env_get_option", r.err)
 }
 
 func (env *Env) SetSyncPeriod(value time.Duration) error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_env_set_syncperiod(env._env, C.uint(NewDuration16dot16(value)))
 	return operrno("mdbx_env_set_syncperiod", ret)
 }
 
 func (env *Env) GetSyncPeriod() (time.Duration, error) {
+	if err := env.checkFork(); err != nil {
+		return 0, err
+	}
 	r := C.mdbxgo_env_get_syncperiod(env._env)
 	return Duration16dot16(r.val).ToDuration(), operrno("mdbx_env_get_syncperiod", r.err)
 }
 
 func (env *Env) SetSyncBytes(threshold uint) error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_env_set_syncbytes(env._env, C.size_t(threshold))
 	return operrno("mdbx_env_set_syncbytes", ret)
 }
 
 func (env *Env) GetSyncBytes() (uint, error) {
+	if err := env.checkFork(); err != nil {
+		return 0, err
+	}
 	r := C.mdbxgo_env_get_syncbytes(env._env)
 	return uint(r.val), operrno("mdbx_env_get_syncbytes", r.err)
 }
 
 func (env *Env) SetGeometry(sizeLower int, sizeNow int, sizeUpper int, growthStep int, shrinkThreshold int, pageSize int) error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_env_set_geometry(env._env,
 		C.intptr_t(sizeLower),
 		C.intptr_t(sizeNow),
@@ -553,7 +744,8 @@ func GetSysRamInfo() (pageSize, totalPages, availablePages int, err error) {
 	return int(r.pageSize), int(r.totalPages), int(r.availPages), nil
 }
 
-// MaxKeySize returns the maximum allowed length for a key.
+// MaxKeySize returns the maximum allowed length for a key of a table without
+// DupSort.  See Limits for the other limits and table flags.
 //
 // See mdbx_env_get_maxkeysize.
 func (env *Env) MaxKeySize() int {
@@ -680,7 +872,12 @@ func (env *Env) run(flags uint, fn TxnOp) error {
 //
 // See mdbx_dbi_close.
 func (env *Env) CloseDBI(db DBI) {
-	C.mdbx_dbi_close(env._env, C.MDBX_dbi(db))
+	if env.checkFork() != nil {
+		return
+	}
+	if C.mdbx_dbi_close(env._env, C.MDBX_dbi(db)) == success {
+		env.cmps.release(db, false)
+	}
 }
 
 func (env *Env) CHandle() unsafe.Pointer {
@@ -0,0 +1,215 @@
+//go:build linux && (amd64 || arm64)
+
+package mdbx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erigontech/mdbx-go/mdbx/internal/forktest"
+)
+
+// forkChildren run in the child of a fork made by TestForkHelper, given the
+// Env of the helper process, a read transaction begun before the fork and
+// the table "fork", which holds the key "k".  Each returns the exit code of
+// the child.
+var forkChildren = map[string]func(env *Env, inherited *Txn, db DBI) int{
+	// resurrect shows the pattern for a child process that inherits an open
+	// Env: resurrect it before anything else, then abort the read
+	// transactions inherited along with it.
+	"resurrect": func(env *Env, inherited *Txn, db DBI) int {
+		if _, err := env.BeginTxn(nil, Readonly); !errors.Is(err, ErrForked) {
+			return 10
+		}
+		if _, err := env.Info(nil); !errors.Is(err, ErrForked) {
+			return 11
+		}
+		if err := env.ResurrectAfterFork(); err != nil {
+			return 12
+		}
+		inherited.Abort()
+		err := env.Update(func(txn *Txn) error {
+			v, err := txn.Get(db, []byte("k"))
+			if err != nil || string(v) != "parent" {
+				return errors.New("unexpected value")
+			}
+			return txn.Put(db, []byte("k"), []byte("child"), 0)
+		})
+		if err != nil {
+			return 13
+		}
+		if err := env.Close(); err != nil {
+			return 14
+		}
+		return 0
+	},
+	// guards uses the Env and the inherited Txn before resurrecting them,
+	// which must fail without spoiling the resurrection.
+	"guards": func(env *Env, inherited *Txn, db DBI) int {
+		if _, err := env.ReaderCheck(); !errors.Is(err, ErrForked) {
+			return 10
+		}
+		path, err := env.Path()
+		if !errors.Is(err, ErrForked) {
+			return 11
+		}
+		if err := env.Copy(filepath.Join(os.TempDir(), "mdbxgo-fork-copy"), 0); !errors.Is(err, ErrForked) {
+			return 12
+		}
+		if _, err := inherited.Get(db, []byte("k")); !errors.Is(err, ErrForked) {
+			return 13
+		}
+		if err := inherited.Renew(); !errors.Is(err, ErrForked) {
+			return 14
+		}
+		if err := env.ResurrectAfterFork(); err != nil {
+			return 15
+		}
+		inherited.Abort()
+		if path, err = env.Path(); err != nil || path == "" {
+			return 16
+		}
+		err = env.View(func(txn *Txn) error {
+			v, err := txn.Get(db, []byte("k"))
+			if err != nil || string(v) != "parent" {
+				return errors.New("unexpected value")
+			}
+			return nil
+		})
+		if err != nil {
+			return 17
+		}
+		if err := env.Close(); err != nil {
+			return 18
+		}
+		return 0
+	},
+	// close releases the copy of the child without resurrecting it.
+	"close": func(env *Env, _ *Txn, _ DBI) int {
+		if err := env.Sync(true, false); !errors.Is(err, ErrForked) {
+			return 10
+		}
+		if err := env.Close(); err != nil {
+			return 11
+		}
+		return 0
+	},
+}
+
+// runForked runs the fork child named child on the database at path.  The
+// fork is made by a new process of the test binary, so that this one never
+// forks its Go runtime.
+func runForked(t *testing.T, path, child string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("forks a helper process")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, os.Args[0], "-test.run=^TestForkHelper$")
+	cmd.Env = append(os.Environ(),
+		"MDBXGO_FORK_CHILD="+child, "MDBXGO_FORK_PATH="+path,
+		// keep the helper as idle as possible at the fork.
+		"GOGC=off", "GOMAXPROCS=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("helper: %v\n%s", err, out)
+	}
+}
+
+// TestForkHelper runs in the helper process of runForked.  It opens the Env,
+// forks and exits with the code of the child.
+func TestForkHelper(t *testing.T) {
+	child := forkChildren[os.Getenv("MDBXGO_FORK_CHILD")]
+	if child == nil {
+		t.Skip("helper process of runForked")
+	}
+	env, err := NewEnv(Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = env.SetOption(OptMaxDB, 1024); err != nil {
+		t.Fatal(err)
+	}
+	if err = env.Open(os.Getenv("MDBXGO_FORK_PATH"), 0, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	inherited, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := inherited.OpenDBISimple("fork", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := forktest.Fork(func() int {
+		return child(env, inherited, db)
+	}, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 0 {
+		t.Fatalf("child exited with %d", code)
+	}
+	// the parent is unaffected.
+	if err = env.ResurrectAfterFork(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = inherited.Get(db, []byte("k")); err != nil {
+		t.Fatal(err)
+	}
+	inherited.Abort()
+	if err = env.Close(); err != nil {
+		t.Fatal(err)
+	}
+	os.Exit(0)
+}
+
+func setupFork(t *testing.T) (*Env, DBI, string) {
+	t.Helper()
+	env, path := setup(t)
+	db := mustOpenUniqueDB(t, env, "fork")
+	if err := env.Update(func(txn *Txn) error {
+		return txn.Put(db, []byte("k"), []byte("parent"), 0)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return env, db, path
+}
+
+func TestEnv_ResurrectAfterFork(t *testing.T) {
+	env, db, path := setupFork(t)
+	runForked(t, path, "resurrect")
+
+	// the commit of the child is seen here.
+	err := env.View(func(txn *Txn) error {
+		v, err := txn.Get(db, []byte("k"))
+		if err != nil {
+			return err
+		}
+		if string(v) != "child" {
+			t.Errorf("unexpected value %q", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnv_ForkGuards(t *testing.T) {
+	_, _, path := setupFork(t)
+	runForked(t, path, "guards")
+}
+
+func TestEnv_CloseAfterFork(t *testing.T) {
+	env, _, path := setupFork(t)
+	runForked(t, path, "close")
+	if _, err := env.Info(nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
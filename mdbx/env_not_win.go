@@ -7,6 +7,37 @@ package mdbx
 */
 import "C"
 
+func init() {
+	if ret := C.mdbxgo_install_fork_guard(); ret != 0 {
+		panic(operrno("pthread_atfork", ret))
+	}
+}
+
+// ResurrectAfterFork makes env, inherited by a child process over a fork,
+// usable in the child.  Until then the Env, and the transactions and cursors
+// inherited along with it, fail with ErrForked, apart from Close, which
+// releases the child's copy.  Only forks through the C library, as in cgo
+// code, are detected, since Go itself only forks to exec; a child of a raw
+// fork system call must call ResurrectAfterFork before using env.
+// Note that the Go runtime of a forked child has a single thread and may
+// deadlock, so the child should do little more than call into C.
+//
+// Transactions inherited along with env stay unusable: a write transaction
+// is aborted by ResurrectAfterFork, while read transactions must be aborted
+// after it to release their resources.  In the parent and in a process that
+// did not fork ResurrectAfterFork does nothing.  An Env opened in Exclusive
+// mode cannot be resurrected and fails with Busy.
+//
+// See mdbx_env_resurrect_after_fork.
+func (env *Env) ResurrectAfterFork() error {
+	ret := C.mdbx_env_resurrect_after_fork(env._env)
+	if ret != success {
+		return operrno("mdbx_env_resurrect_after_fork", ret)
+	}
+	env.forks = C.mdbxgo_forks
+	return nil
+}
+
 // Path returns the path argument passed to Open.  Path returns a non-nil error
 // if env.Open() was not previously called.
 //
@@ -14,6 +45,9 @@ import "C"
 //
 //nolint:gocritic // reason: false positive on dupSubExpr
 func (env *Env) Path() (string, error) {
+	if err := env.checkFork(); err != nil {
+		return "", err
+	}
 	var cpath *C.char
 	ret := C.mdbx_env_get_path(env._env, &cpath)
 	if ret != success {
@@ -31,6 +65,9 @@ func (env *Env) Path() (string, error) {
 //
 // See mdbx_env_get_fd.
 func (env *Env) FD() (uintptr, error) {
+	if err := env.checkFork(); err != nil {
+		return 0, err
+	}
 	// fdInvalid is the value -1 as a uintptr, which is used by MDBX in the
 	// case that env has not been opened yet.  the strange construction is done
 	// to avoid constant value overflow errors at compile time.
@@ -1,10 +1,14 @@
 package mdbx
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"runtime"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestEnv_Path_notOpen(t *testing.T) {
@@ -100,6 +104,50 @@ func TestEnv_PreOpen(t *testing.T) {
 	fmt.Printf("%#v\n", _info)
 }
 
+func TestDeleteEnv(t *testing.T) {
+	env, path := setup(t)
+	if runtime.GOOS == "linux" {
+		// other platforms lack the per-descriptor locks to see an Env of
+		// the same process.
+		err := DeleteEnv(path, EnvEnsureUnused)
+		var inUse *EnvInUseError
+		if !errors.As(err, &inUse) || inUse.Path != path {
+			t.Fatalf("unexpected error for an open env: %v", err)
+		}
+		if _, err = os.Stat(path); err != nil {
+			t.Fatal(err)
+		}
+
+		done := make(chan error, 1)
+		go func() { done <- DeleteEnv(path, EnvWaitForUnused) }()
+		select {
+		case err := <-done:
+			t.Fatalf("returned for an open env: %v", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+		env.Close()
+		if err = <-done; err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		env.Close()
+		if err := DeleteEnv(path, EnvEnsureUnused); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the environment is not deleted: %v", err)
+	}
+
+	// a missing environment is no error.
+	if err := DeleteEnv(path, EnvJustDelete); err != nil {
+		t.Error(err)
+	}
+	if err := DeleteEnv(path, DeleteMode(100)); !IsErrnoSys(err, syscall.EINVAL) {
+		t.Errorf("unexpected error for an invalid mode: %v", err)
+	}
+}
+
 func TestEnv_FD(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("FD funcs not supported on windows")
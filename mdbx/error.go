@@ -74,10 +74,27 @@ const (
 	BadValSize      Errno = C.MDBX_BAD_VALSIZE
 	BadDBI          Errno = C.MDBX_BAD_DBI
 	Perm            Errno = C.MDBX_EPERM
+	// MultiValue reports an attempt to replace or delete the only value of a
+	// key in a DupSort table that holds several values for it, e.g. by
+	// Txn.Replace.
+	MultiValue Errno = C.MDBX_EMULTIVAL
 	// Ousted reports that a parked reader was ousted by a writer to
 	// recycle old MVCC snapshots (returned e.g. by Txn.Unpark with
 	// restartIfOusted=false, or by reads in a parked-and-ousted txn).
 	Ousted Errno = C.MDBX_OUSTED
+	// LaggardReader reports that readers holding old MVCC snapshots keep
+	// Env.Defrag from completing.
+	LaggardReader Errno = C.MDBX_LAGGARD_READER
+	// Busy reports a resource held elsewhere, e.g. an Env opened in
+	// Exclusive mode for Env.ResurrectAfterFork.
+	Busy Errno = C.MDBX_BUSY
+	// ThreadMismatch reports a call from another thread than the one which
+	// owns the resource, e.g. WriterLock.Unlock from another goroutine than
+	// the one which called Env.LockWriter.
+	ThreadMismatch Errno = C.MDBX_THREAD_MISMATCH
+	// WannaRecovery reports a database which needs a rollback or sync after
+	// an unclean shutdown, which a read-only open can't do.
+	WannaRecovery Errno = C.MDBX_WANNA_RECOVERY
 	// TLSFull       Errno = C.MDBX_TLS_FULL
 	// MapResized    Errno = C.MDBX_MAP_RESIZED
 )
@@ -94,6 +111,14 @@ var ErrNotFound = errors.New("key not found")
 // which reports that a searched-for key/value does not exist.
 var ErrNoData = errors.New("cursor is not positioned to data")
 
+// ErrRenameRoot is returned by Txn.RenameDBI for the root table, which has no
+// name to change.
+var ErrRenameRoot = errors.New("the root table cannot be renamed")
+
+// ErrForked is returned for an Env used in a child process that inherited it
+// over a fork, until Env.ResurrectAfterFork is called.
+var ErrForked = errors.New("environment inherited over a fork is not resurrected")
+
 // App can re-define this messages from init() func
 var CorruptErrorHardwareRecommendations = "Maybe free space is over on disk. Otherwise it's hardware failure. Before creating issue please use tools like https://www.memtest86.com to test RAM and tools like https://www.smartmontools.org to test Disk. To handle hardware risks: use ECC RAM, use RAID of disks, run multiple application instances (or do backups). If hardware checks passed - check FS settings - 'fsync' and 'flock' must be enabled. "
 var CorruptErrorBacktraceRecommendations = "Otherwise - please create issue in Application repo." // with backtrace or coredump. To create coredump set compile option 'MDBX_FORCE_ASSERTIONS=1' and env variable 'GOTRACEBACK=crash'."
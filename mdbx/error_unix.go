@@ -25,3 +25,9 @@ func operrno(op string, ret C.int) error {
 	}
 	return &OpError{Op: op, Errno: syscall.Errno(ret)}
 }
+
+// isLockConflict reports whether ret is the error of a file lock held by
+// another open file description.
+func isLockConflict(ret C.int) bool {
+	return syscall.Errno(ret) == syscall.EAGAIN
+}
@@ -47,3 +47,9 @@ func operrno(op string, ret C.int) error {
 	}
 	return &OpError{Op: op, Errno: errno}
 }
+
+// isLockConflict reports whether ret is the error of a file locked or
+// mapped by another handle.
+func isLockConflict(ret C.int) bool {
+	return ret == C.ERROR_LOCK_VIOLATION || ret == C.ERROR_SHARING_VIOLATION
+}
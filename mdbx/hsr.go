@@ -0,0 +1,130 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"os"
+	"time"
+)
+
+// SlowReader describes a read transaction that keeps a write transaction
+// from reclaiming old pages, as passed to the callback installed by
+// Env.SetHSR.
+//
+// See MDBX_hsr_func.
+type SlowReader struct {
+	PID     int    // Process ID of the reader
+	TID     uint64 // Thread ID of the reader
+	Laggard uint64 // ID of the MVCC snapshot the reader holds
+	Gap     uint   // Lag of the snapshot behind the last committed transaction
+	Space   uint64 // Bytes that become reusable once the reader is done
+
+	// Retry counts the calls for the same write transaction, starting from
+	// 0.  If the callback returned HSRRetry at least once, it is called once
+	// more with a negative Retry and a zero PID when libmdbx stops asking;
+	// the decision returned for that call is ignored.
+	Retry int
+}
+
+// External reports whether the reader belongs to another process.
+func (r SlowReader) External() bool { return r.PID != os.Getpid() }
+
+// HSRDecision tells libmdbx how the callback installed by Env.SetHSR dealt
+// with a slow reader.
+type HSRDecision int
+
+// Decisions of a Handle-Slow-Readers callback.
+const (
+	// HSRGiveUp leaves the reader alone.  libmdbx grows the database if it
+	// still can, otherwise the write fails with MapFull.
+	HSRGiveUp HSRDecision = -1
+	// HSRRetry has libmdbx rescan the reader table.  The callback returns it
+	// after waiting for the reader, or after having the reader finish its
+	// transaction, e.g. by aborting it.
+	HSRRetry HSRDecision = 0
+	// HSROust clears the reader slot, as libmdbx itself does for parked
+	// transactions.  The reader must not read any more data through its
+	// transaction, which may see reused pages, so HSROust is only safe for
+	// readers that are known to be aborted asynchronously.
+	HSROust HSRDecision = 1
+	// HSRKill kills the reader process, which must be another process, and
+	// has libmdbx reset its reader registration.
+	HSRKill HSRDecision = 2
+)
+
+// SetHSR installs fn as the Handle-Slow-Readers callback of env.  It is
+// called by a write transaction running out of space because readers keep
+// old pages from being reclaimed, before the database is grown or the write
+// fails with MapFull.  A nil fn removes the callback.
+//
+// fn runs on the goroutine of the write transaction, while it holds the
+// writer lock.  A panic in fn is recovered and treated as HSRGiveUp.
+// Parked readers (see Txn.Park) are ousted by libmdbx without asking fn.
+//
+// See mdbx_env_set_hsr.
+func (env *Env) SetHSR(fn func(SlowReader) HSRDecision) error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
+	if fn == nil {
+		ret := C.mdbxgo_env_set_hsr(env._env, false)
+		env.hsr.Store(nil)
+		return operrno("mdbx_env_set_hsr", ret)
+	}
+	hsr := hsrfunc(fn)
+	env.hsr.Store(&hsr)
+	ret := C.mdbxgo_env_set_hsr(env._env, true)
+	if ret != success {
+		env.hsr.Store(nil)
+	}
+	return operrno("mdbx_env_set_hsr", ret)
+}
+
+// HSRPolicy is a reusable Handle-Slow-Readers policy: it waits a while for
+// slow readers to finish and then gives up or, if allowed, kills external
+// readers.  Its Handle method may be passed to Env.SetHSR.
+type HSRPolicy struct {
+	// Interval is the pause before each rescan of the reader table.
+	Interval time.Duration
+	// MaxWait is the total time to wait for a reader before giving up.
+	MaxWait time.Duration
+	// KillExternal kills readers of other processes instead of giving up
+	// once MaxWait has passed.
+	KillExternal bool
+}
+
+// DefaultHSRPolicy waits up to a second for slow readers to finish.
+var DefaultHSRPolicy = HSRPolicy{
+	Interval: 10 * time.Millisecond,
+	MaxWait:  time.Second,
+}
+
+// Handle decides about r according to p.
+func (p HSRPolicy) Handle(r SlowReader) HSRDecision {
+	if r.Retry < 0 {
+		return HSRRetry
+	}
+	if p.Interval > 0 && time.Duration(r.Retry)*p.Interval < p.MaxWait {
+		time.Sleep(p.Interval)
+		return HSRRetry
+	}
+	if p.KillExternal && r.External() {
+		return HSRKill
+	}
+	return HSRGiveUp
+}
+
+// killReader kills the process of a slow reader for HSRKill.
+func killReader(pid int) error {
+	if pid == os.Getpid() {
+		return os.ErrPermission
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
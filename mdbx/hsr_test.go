@@ -0,0 +1,175 @@
+package mdbx
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEnv_SetHSR_giveUp(t *testing.T) {
+	env, dbi := setupHSREnv(t)
+
+	reader, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer reader.Abort()
+
+	var calls []SlowReader
+	err = env.SetHSR(func(r SlowReader) HSRDecision {
+		calls = append(calls, r)
+		return HSRGiveUp
+	})
+	if err != nil {
+		t.Fatalf("set hsr: %v", err)
+	}
+	if err = rewriteUntilFull(env, dbi); !IsMapFull(err) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) == 0 {
+		t.Fatalf("callback was never called")
+	}
+	r := calls[0]
+	if r.PID != os.Getpid() || r.External() || r.Laggard != reader.ID() || r.Gap == 0 || r.Retry != 0 {
+		t.Errorf("unexpected slow reader: %+v (reader txn %d)", r, reader.ID())
+	}
+}
+
+func TestEnv_SetHSR_retry(t *testing.T) {
+	env, dbi := setupHSREnv(t)
+
+	reader, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer reader.Abort()
+
+	var calls, ends int
+	err = env.SetHSR(func(r SlowReader) HSRDecision {
+		if r.Retry < 0 {
+			ends++
+			return HSRGiveUp
+		}
+		calls++
+		reader.Abort()
+		return HSRRetry
+	})
+	if err != nil {
+		t.Fatalf("set hsr: %v", err)
+	}
+	if err = rewrite(env, dbi, 1000); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if calls != 1 || ends != 1 {
+		t.Errorf("callback called %d times, loop ended %d times", calls, ends)
+	}
+}
+
+func TestEnv_SetHSR_remove(t *testing.T) {
+	env, dbi := setupHSREnv(t)
+
+	reader, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer reader.Abort()
+
+	var called bool
+	err = env.SetHSR(func(SlowReader) HSRDecision {
+		called = true
+		panic("slow reader")
+	})
+	if err != nil {
+		t.Fatalf("set hsr: %v", err)
+	}
+	if err = rewriteUntilFull(env, dbi); !IsMapFull(err) || !called {
+		t.Fatalf("unexpected error: %v (called %t)", err, called)
+	}
+
+	called = false
+	if err = env.SetHSR(nil); err != nil {
+		t.Fatalf("remove hsr: %v", err)
+	}
+	if err = rewriteUntilFull(env, dbi); !IsMapFull(err) || called {
+		t.Fatalf("unexpected error: %v (called %t)", err, called)
+	}
+}
+
+func TestHSRPolicy(t *testing.T) {
+	p := HSRPolicy{Interval: time.Millisecond, MaxWait: 3 * time.Millisecond}
+	own := SlowReader{PID: os.Getpid()}
+	other := SlowReader{PID: os.Getpid() + 1}
+	for _, test := range []struct {
+		p    HSRPolicy
+		r    SlowReader
+		want HSRDecision
+	}{
+		{p, own, HSRRetry},
+		{p, SlowReader{PID: own.PID, Retry: 2}, HSRRetry},
+		{p, SlowReader{PID: own.PID, Retry: 3}, HSRGiveUp},
+		{p, SlowReader{Retry: -3}, HSRRetry},
+		{HSRPolicy{}, own, HSRGiveUp},
+		{HSRPolicy{KillExternal: true}, own, HSRGiveUp},
+		{HSRPolicy{KillExternal: true}, other, HSRKill},
+	} {
+		if got := test.p.Handle(test.r); got != test.want {
+			t.Errorf("%+v.Handle(%+v) = %d (!= %d)", test.p, test.r, got, test.want)
+		}
+	}
+}
+
+// setupHSREnv opens an environment limited to 1MB with a table of 16
+// entries.
+func setupHSREnv(t *testing.T) (*Env, DBI) {
+	t.Helper()
+	env, err := NewEnv(Default)
+	if err != nil {
+		t.Fatalf("env: %v", err)
+	}
+	if err = env.SetGeometry(-1, -1, 1<<20, -1, -1, 4096); err != nil {
+		t.Fatalf("geometry: %v", err)
+	}
+	if err = env.Open(t.TempDir(), 0, 0664); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { env.Close() })
+
+	var dbi DBI
+	err = env.Update(func(txn *Txn) (err error) {
+		dbi, err = txn.OpenRoot(0)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = rewrite(env, dbi, 1); err != nil {
+		t.Fatal(err)
+	}
+	return env, dbi
+}
+
+// rewrite overwrites the entries of dbi n times, each in a transaction of its
+// own, which retires their old pages every time.
+func rewrite(env *Env, dbi DBI, n int) error {
+	value := make([]byte, 2048)
+	for i := range n {
+		err := env.Update(func(txn *Txn) error {
+			for k := range 16 {
+				value[0] = byte(i)
+				if err := txn.Put(dbi, fmt.Appendf(nil, "key-%02d", k), value, 0); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rewriteUntilFull(env *Env, dbi DBI) error {
+	return rewrite(env, dbi, 1000)
+}
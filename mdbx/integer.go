@@ -0,0 +1,155 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import "unsafe"
+
+// The keys of IntegerKey tables and the values of IntegerDup tables are
+// unsigned integers of 4 or 8 bytes in native byte order, as written by
+// binary.NativeEndian, which order by value.  All keys of a table, and all
+// values, have the same size.  The methods below take the integers by value
+// and pass them to libmdbx without allocating, as Get and Put would for a
+// slice encoding them.  Keys and values they return are views into the
+// database, see Get, and decode with binary.NativeEndian.  The values of
+// IntegerDup tables are read in bulk with GetMultiple and NextMultiple, and
+// WrapMulti with a stride of 4 or 8.
+
+// GetUint32 is Get for the 4-byte integer key of an IntegerKey table.
+func (txn *Txn) GetUint32(dbi DBI, key uint32) ([]byte, error) {
+	return txn.getInt(dbi, uint64(key), 4)
+}
+
+// GetUint64 is Get for the 8-byte integer key of an IntegerKey table.
+func (txn *Txn) GetUint64(dbi DBI, key uint64) ([]byte, error) {
+	return txn.getInt(dbi, key, 8)
+}
+
+func (txn *Txn) getInt(dbi DBI, key uint64, kn int) ([]byte, error) {
+	r := C.mdbxgo_get_int(txn._txn, C.MDBX_dbi(dbi), C.uint64_t(key), C.size_t(kn))
+	if err := txn.operrno("mdbx_get", r.err); err != nil {
+		return nil, err
+	}
+	return castToBytesRaw(unsafe.Pointer(r.vbase), r.vlen), nil
+}
+
+// PutUint32 is Put for the 4-byte integer key of an IntegerKey table.
+func (txn *Txn) PutUint32(dbi DBI, key uint32, val []byte, flags uint) error {
+	return txn.putInt(dbi, uint64(key), 4, val, flags)
+}
+
+// PutUint64 is Put for the 8-byte integer key of an IntegerKey table.
+func (txn *Txn) PutUint64(dbi DBI, key uint64, val []byte, flags uint) error {
+	return txn.putInt(dbi, key, 8, val, flags)
+}
+
+func (txn *Txn) putInt(dbi DBI, key uint64, kn int, val []byte, flags uint) error {
+	var v *C.char
+	if len(val) > 0 {
+		v = (*C.char)(unsafe.Pointer(&val[0]))
+	}
+	ret := C.mdbxgo_put_int(
+		txn._txn, C.MDBX_dbi(dbi),
+		C.uint64_t(key), C.size_t(kn),
+		v, C.size_t(len(val)),
+		C.MDBX_put_flags_t(flags),
+	)
+	return txn.operrno("mdbx_put", ret)
+}
+
+// PutUint32Pair is Put for a 4-byte integer key and value, as in an
+// IntegerKey table with IntegerDup values.
+func (txn *Txn) PutUint32Pair(dbi DBI, key, val uint32, flags uint) error {
+	ret := C.mdbxgo_put_ints(txn._txn, C.MDBX_dbi(dbi), C.uint64_t(key), C.uint64_t(val), 4, C.MDBX_put_flags_t(flags))
+	return txn.operrno("mdbx_put", ret)
+}
+
+// PutUint64Pair is Put for an 8-byte integer key and value, as in an
+// IntegerKey table with IntegerDup values.
+func (txn *Txn) PutUint64Pair(dbi DBI, key, val uint64, flags uint) error {
+	ret := C.mdbxgo_put_ints(txn._txn, C.MDBX_dbi(dbi), C.uint64_t(key), C.uint64_t(val), 8, C.MDBX_put_flags_t(flags))
+	return txn.operrno("mdbx_put", ret)
+}
+
+// GetUint32 is Get for ops which take the 4-byte integer key of an IntegerKey
+// table, such as Set, SetKey and SetRange.  Unlike Get, the returned key is
+// nil for ops which do not return one, such as Set.
+func (c *Cursor) GetUint32(setkey uint32, op uint) (key, val []byte, err error) {
+	return c.getResult(C.mdbxgo_cursor_get_int(c._c, C.uint64_t(setkey), 4, C.MDBX_cursor_op(op)))
+}
+
+// GetUint64 is Get for ops which take the 8-byte integer key of an IntegerKey
+// table, such as Set, SetKey and SetRange.  Unlike Get, the returned key is
+// nil for ops which do not return one, such as Set.
+func (c *Cursor) GetUint64(setkey uint64, op uint) (key, val []byte, err error) {
+	return c.getResult(C.mdbxgo_cursor_get_int(c._c, C.uint64_t(setkey), 8, C.MDBX_cursor_op(op)))
+}
+
+// GetUint32Pair is Get for ops which take a 4-byte integer key and value,
+// such as GetBoth and GetBothRange in an IntegerKey table with IntegerDup
+// values.  Unlike Get, the returned key and val are nil for ops which do not
+// return them.
+func (c *Cursor) GetUint32Pair(setkey, setval uint32, op uint) (key, val []byte, err error) {
+	return c.getResult(C.mdbxgo_cursor_get_ints(c._c, C.uint64_t(setkey), C.uint64_t(setval), 4, C.MDBX_cursor_op(op)))
+}
+
+// GetUint64Pair is Get for ops which take an 8-byte integer key and value,
+// such as GetBoth and GetBothRange in an IntegerKey table with IntegerDup
+// values.  Unlike Get, the returned key and val are nil for ops which do not
+// return them.
+func (c *Cursor) GetUint64Pair(setkey, setval uint64, op uint) (key, val []byte, err error) {
+	return c.getResult(C.mdbxgo_cursor_get_ints(c._c, C.uint64_t(setkey), C.uint64_t(setval), 8, C.MDBX_cursor_op(op)))
+}
+
+func (c *Cursor) getResult(r C.mdbxgo_val_result) (key, val []byte, err error) {
+	if err := c.operrno("mdbx_cursor_get", r.err); err != nil {
+		return nil, nil, err
+	}
+	if r.kbase != nil {
+		key = castToBytesRaw(unsafe.Pointer(r.kbase), r.klen)
+	}
+	if r.vbase != nil {
+		val = castToBytesRaw(unsafe.Pointer(r.vbase), r.vlen)
+	}
+	return key, val, nil
+}
+
+// PutUint32 is Put for the 4-byte integer key of an IntegerKey table.
+func (c *Cursor) PutUint32(key uint32, val []byte, flags uint) error {
+	return c.putInt(uint64(key), 4, val, flags)
+}
+
+// PutUint64 is Put for the 8-byte integer key of an IntegerKey table.
+func (c *Cursor) PutUint64(key uint64, val []byte, flags uint) error {
+	return c.putInt(key, 8, val, flags)
+}
+
+func (c *Cursor) putInt(key uint64, kn int, val []byte, flags uint) error {
+	var v *C.char
+	if len(val) > 0 {
+		v = (*C.char)(unsafe.Pointer(&val[0]))
+	}
+	ret := C.mdbxgo_cursor_put_int(
+		c._c,
+		C.uint64_t(key), C.size_t(kn),
+		v, C.size_t(len(val)),
+		C.MDBX_put_flags_t(flags),
+	)
+	return c.operrno("mdbx_cursor_put", ret)
+}
+
+// PutUint32Pair is Put for a 4-byte integer key and value, as in an
+// IntegerKey table with IntegerDup values.
+func (c *Cursor) PutUint32Pair(key, val uint32, flags uint) error {
+	ret := C.mdbxgo_cursor_put_ints(c._c, C.uint64_t(key), C.uint64_t(val), 4, C.MDBX_put_flags_t(flags))
+	return c.operrno("mdbx_cursor_put", ret)
+}
+
+// PutUint64Pair is Put for an 8-byte integer key and value, as in an
+// IntegerKey table with IntegerDup values.
+func (c *Cursor) PutUint64Pair(key, val uint64, flags uint) error {
+	ret := C.mdbxgo_cursor_put_ints(c._c, C.uint64_t(key), C.uint64_t(val), 8, C.MDBX_put_flags_t(flags))
+	return c.operrno("mdbx_cursor_put", ret)
+}
@@ -0,0 +1,306 @@
+package mdbx
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestTxn_IntegerKey(t *testing.T) {
+	env, _ := setup(t)
+	keys := []uint64{256, 1, 1 << 40, 255, 0, 1<<64 - 1, 1 << 32}
+	var db, db32 DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		if db, err = txn.OpenDBISimple("ints", Create|IntegerKey); err != nil {
+			return err
+		}
+		if db32, err = txn.OpenDBISimple("ints32", Create|IntegerKey); err != nil {
+			return err
+		}
+		cur, err := txn.OpenCursor(db32)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		for i, k := range keys {
+			if err := txn.PutUint64(db, k, []byte{byte(i)}, 0); err != nil {
+				return err
+			}
+			if err := cur.PutUint32(uint32(k), []byte{byte(i)}, 0); err != nil {
+				return err
+			}
+		}
+		if err := txn.PutUint32(db, 7, nil, 0); !IsErrno(err, BadValSize) {
+			t.Errorf("unexpected error for a key of another size: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		for i, k := range keys {
+			v, err := txn.GetUint64(db, k)
+			if err != nil || !slices.Equal(v, []byte{byte(i)}) {
+				t.Errorf("key %d: unexpected value %v %v", k, v, err)
+			}
+		}
+		if _, err := txn.GetUint64(db, 2); !IsNotFound(err) {
+			t.Errorf("unexpected error for a missing key: %v", err)
+		}
+		if v, err := txn.GetUint32(db32, 255); err != nil || !slices.Equal(v, []byte{3}) {
+			t.Errorf("unexpected uint32 value %v %v", v, err)
+		}
+
+		// keys order by value, not bytewise.
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		var got []uint64
+		for op := uint(First); ; op = Next {
+			k, _, err := cur.Get(nil, nil, op)
+			if IsNotFound(err) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			got = append(got, binary.NativeEndian.Uint64(k))
+		}
+		expect := slices.Sorted(slices.Values(keys))
+		if !slices.Equal(got, expect) {
+			t.Errorf("unexpected order %v", got)
+		}
+
+		k, v, err := cur.GetUint64(257, SetRange)
+		if err != nil || binary.NativeEndian.Uint64(k) != 1<<32 || !slices.Equal(v, []byte{6}) {
+			t.Errorf("unexpected SetRange result %x %v %v", k, v, err)
+		}
+		k, v, err = cur.GetUint64(255, Set)
+		if err != nil || k != nil || !slices.Equal(v, []byte{3}) {
+			t.Errorf("unexpected Set result %x %v %v", k, v, err)
+		}
+		k, _, err = cur.GetUint64(255, SetKey)
+		if err != nil || binary.NativeEndian.Uint64(k) != 255 {
+			t.Errorf("unexpected SetKey result %x %v", k, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_IntegerDup(t *testing.T) {
+	env, _ := setup(t)
+	rng := rand.New(rand.NewSource(1))
+	for _, stride := range []int{4, 8} {
+		name := "dups" + string(rune('0'+stride))
+		vals := map[uint64][]uint64{}
+		for k := range uint64(3) {
+			// enough values to take several pages.
+			for range 3000 {
+				v := rng.Uint64()
+				if stride == 4 {
+					v = uint64(rng.Uint32())
+				}
+				vals[k] = append(vals[k], v)
+			}
+		}
+
+		var db DBI
+		err := env.Update(func(txn *Txn) (err error) {
+			db, err = txn.OpenDBISimple(name, Create|IntegerKey|DupSort|DupFixed|IntegerDup)
+			if err != nil {
+				return err
+			}
+			cur, err := txn.OpenCursor(db)
+			if err != nil {
+				return err
+			}
+			defer cur.Close()
+			for k, vs := range vals {
+				for i, v := range vs {
+					switch {
+					case stride == 4 && i%2 == 0:
+						err = txn.PutUint32Pair(db, uint32(k), uint32(v), 0)
+					case stride == 4:
+						err = cur.PutUint32Pair(uint32(k), uint32(v), 0)
+					case i%2 == 0:
+						err = txn.PutUint64Pair(db, k, v, 0)
+					default:
+						err = cur.PutUint64Pair(k, v, 0)
+					}
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("stride %d: %v", stride, err)
+		}
+
+		err = env.View(func(txn *Txn) error {
+			cur, err := txn.OpenCursor(db)
+			if err != nil {
+				return err
+			}
+			defer cur.Close()
+
+			v0 := vals[1][0]
+			var k, v []byte
+			if stride == 4 {
+				k, v, err = cur.GetUint32Pair(1, uint32(v0), GetBoth)
+			} else {
+				k, v, err = cur.GetUint64Pair(1, v0, GetBoth)
+			}
+			if err != nil || k != nil || len(v) != stride {
+				t.Errorf("stride %d: unexpected GetBoth result %x %x %v", stride, k, v, err)
+			}
+			if stride == 4 {
+				_, v, err = cur.GetUint32Pair(1, uint32(v0)+1, GetBothRange)
+			} else {
+				_, v, err = cur.GetUint64Pair(1, v0+1, GetBothRange)
+			}
+			if err != nil || len(v) != stride {
+				t.Errorf("stride %d: unexpected GetBothRange result %x %v", stride, v, err)
+			}
+
+			// the values of each key order by value, a page at a time.
+			got := map[uint64][]uint64{}
+			for op := uint(First); ; op = NextNoDup {
+				k, _, err := cur.Get(nil, nil, op)
+				if IsNotFound(err) {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				var key uint64
+				if stride == 4 {
+					key = uint64(binary.NativeEndian.Uint32(k))
+				} else {
+					key = binary.NativeEndian.Uint64(k)
+				}
+				for mop := uint(GetMultiple); ; mop = NextMultiple {
+					_, page, err := cur.Get(nil, nil, mop)
+					if IsNotFound(err) {
+						break
+					}
+					if err != nil {
+						return err
+					}
+					m := WrapMulti(page, stride)
+					for i := range m.Len() {
+						if stride == 4 {
+							got[key] = append(got[key], uint64(binary.NativeEndian.Uint32(m.Val(i))))
+						} else {
+							got[key] = append(got[key], binary.NativeEndian.Uint64(m.Val(i)))
+						}
+					}
+				}
+			}
+			for k, vs := range vals {
+				slices.Sort(vs)
+				if vs = slices.Compact(vs); !slices.Equal(got[k], vs) {
+					t.Errorf("stride %d: key %d has %d values out of %d, or out of order", stride, k, len(got[k]), len(vs))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("stride %d: %v", stride, err)
+		}
+	}
+}
+
+func TestTxn_Uint64_NoAllocs(t *testing.T) {
+	env, _ := setup(t)
+	err := env.Update(func(txn *Txn) error {
+		db, err := txn.OpenDBISimple("ints_noalloc", Create|IntegerKey|DupSort|DupFixed|IntegerDup)
+		if err != nil {
+			return err
+		}
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		var i uint64
+		assertNoAllocs(t, "Txn.PutUint64Pair()", func() { i++; _ = txn.PutUint64Pair(db, i%7, i, 0) })
+		assertNoAllocs(t, "Cursor.PutUint64Pair()", func() { i++; _ = cur.PutUint64Pair(i%7, i, 0) })
+		assertNoAllocs(t, "Txn.GetUint64()", func() { _, _ = txn.GetUint64(db, 3) })
+		assertNoAllocs(t, "Cursor.GetUint64()", func() { _, _, _ = cur.GetUint64(3, SetKey) })
+		assertNoAllocs(t, "Cursor.GetUint64Pair()", func() { _, _, _ = cur.GetUint64Pair(3, 10, GetBothRange) })
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkTxn_GetUint64(b *testing.B) {
+	env, _ := setup(b)
+	const n = 100000
+	var intDB, beDB DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		if intDB, err = txn.OpenDBISimple("integerkey", Create|IntegerKey); err != nil {
+			return err
+		}
+		if beDB, err = txn.OpenDBISimple("bigendian", Create); err != nil {
+			return err
+		}
+		for i := range uint64(n) {
+			if err := txn.PutUint64(intDB, i*7919, nil, 0); err != nil {
+				return err
+			}
+			if err := txn.Put(beDB, binary.BigEndian.AppendUint64(nil, i*7919), nil, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("IntegerKey", func(b *testing.B) {
+		err := env.View(func(txn *Txn) error {
+			i := uint64(0)
+			for b.Loop() {
+				if _, err := txn.GetUint64(intDB, i*4099%n*7919); err != nil {
+					return err
+				}
+				i++
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	})
+	b.Run("BigEndian", func(b *testing.B) {
+		err := env.View(func(txn *Txn) error {
+			i := uint64(0)
+			for b.Loop() {
+				var k [8]byte
+				binary.BigEndian.PutUint64(k[:], i*4099%n*7919)
+				if _, err := txn.Get(beDB, k[:]); err != nil {
+					return err
+				}
+				i++
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+//go:build linux && (amd64 || arm64)
+
+// Package forktest forks the process through the C library for the tests of
+// Env.ResurrectAfterFork, which Go itself cannot do.  It is a best-effort
+// test helper: it clones the Go runtime with a raw clone system call, whose
+// arguments differ between architectures, and relies on the runtime staying
+// idle in the copies, which Go does not promise.  So it is built for linux on
+// amd64 and arm64 only.
+package forktest
+
+/*
+#include <unistd.h>
+*/
+import "C"
+
+import (
+	"runtime"
+	"runtime/debug"
+	"syscall"
+	"time"
+)
+
+// Fork runs child in a process forked by fork(3), so that the pthread_atfork
+// handlers run as they do for forks by C code, and returns the code child
+// returns, which the process exits with.  Fork returns -1 if the process is
+// killed by a signal, or by Fork after timeout.
+//
+// child runs in a process with a single thread and the garbage collector
+// disabled, so it should do little more than call into C.  As Fork relies on
+// the runtime staying idle in the copies of the process, it is meant for a
+// helper process started by the test, not for the process running the tests.
+func Fork(child func() int, timeout time.Duration) (int, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	// the child lacks the threads the collector would stop.
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+
+	// A cgo call may lose its P to another thread while it blocks, which the
+	// child of a fork in the call then waits for in vain.  So the process
+	// is first cloned without leaving Go, and calls fork(3) once it is down
+	// to one thread.  Likewise a preemption requested before the clone would
+	// have the child wait for another thread to run the goroutine, hence
+	// the yield, which clears any.
+	runtime.Gosched()
+	pid, _, errno := syscall.RawSyscall6(syscall.SYS_CLONE, uintptr(syscall.SIGCHLD), 0, 0, 0, 0, 0)
+	if errno != 0 {
+		return 0, errno
+	}
+	if pid == 0 {
+		pid, err := C.fork()
+		switch {
+		case pid < 0:
+			syscall.Exit(int(err.(syscall.Errno)))
+		case pid == 0:
+			syscall.Exit(child())
+		}
+		var ws syscall.WaitStatus
+		if _, err := syscall.Wait4(int(pid), &ws, 0, nil); err != nil || !ws.Exited() {
+			syscall.Exit(255)
+		}
+		syscall.Exit(ws.ExitStatus())
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		var ws syscall.WaitStatus
+		wpid, err := syscall.Wait4(int(pid), &ws, syscall.WNOHANG, nil)
+		if err != nil {
+			return 0, err
+		}
+		if wpid == int(pid) {
+			if !ws.Exited() {
+				return -1, nil
+			}
+			return ws.ExitStatus(), nil
+		}
+		if time.Now().After(deadline) {
+			_ = syscall.Kill(int(pid), syscall.SIGKILL)
+			_, _ = syscall.Wait4(int(pid), &ws, 0, nil)
+			return -1, nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,190 @@
+package mdbx
+
+import (
+	"bytes"
+	"iter"
+)
+
+// Bound is the lower or upper bound of the keys of a range, see Txn.Range.
+// The zero Bound leaves its end of the range open.
+type Bound struct {
+	key  []byte
+	excl bool
+	set  bool
+}
+
+// Including bounds a range at key, which is in the range.
+func Including(key []byte) Bound {
+	return Bound{key: key, set: true}
+}
+
+// Excluding bounds a range at key, which is not in the range.
+func Excluding(key []byte) Bound {
+	return Bound{key: key, excl: true, set: true}
+}
+
+// Range iterates over the pairs of dbi with keys from the bound from to the
+// bound to, in order, all the values of a DupSort key in turn.  The keys are
+// compared with the upper bound by bytes.Compare, or by Txn.Cmp in tables with
+// the ReverseKey or IntegerKey flag or a comparator given to
+// OpenDBIWithCompare or OpenDBI, which costs another cgo call per pair.  An
+// excluded bound of a DupSort table excludes all its values.
+//
+// The iterator is returned with a function reporting the error which ended
+// the last iteration early, or nil once the range was exhausted or the loop
+// broke off.
+//
+//	pairs, errf := txn.Range(dbi, mdbx.Including(from), mdbx.Excluding(to))
+//	for k, v := range pairs {
+//		...
+//	}
+//	if err := errf(); err != nil {
+//		...
+//	}
+//
+// Each iteration takes a cursor from the pool and returns it at the end, see
+// CursorFromPool.  The keys and values are views into the database, see
+// Txn.Get.
+func (txn *Txn) Range(dbi DBI, from, to Bound) (iter.Seq2[[]byte, []byte], func() error) {
+	var err error
+	seq := func(yield func(k, v []byte) bool) {
+		err = txn.iterate(dbi, func(c *Cursor) error {
+			cmp := txn.keyCmp(dbi, to)
+			k, v, err := c.seek(from, false)
+			for ; err == nil; k, v, err = c.Get(nil, nil, Next) {
+				if to.set && !to.admits(cmp(to.key, k)) || !yield(k, v) {
+					return nil
+				}
+			}
+			return err
+		})
+	}
+	return seq, func() error { return err }
+}
+
+// Reverse is Range in reverse order, from the bound to down to the bound
+// from, the values of a DupSort key from the last.  The keys are compared with
+// the lower bound as in Range.
+func (txn *Txn) Reverse(dbi DBI, from, to Bound) (iter.Seq2[[]byte, []byte], func() error) {
+	var err error
+	seq := func(yield func(k, v []byte) bool) {
+		err = txn.iterate(dbi, func(c *Cursor) error {
+			cmp := txn.keyCmp(dbi, from)
+			k, v, err := c.seek(to, true)
+			for ; err == nil; k, v, err = c.Get(nil, nil, Prev) {
+				if from.set && !from.admits(cmp(k, from.key)) || !yield(k, v) {
+					return nil
+				}
+			}
+			return err
+		})
+	}
+	return seq, func() error { return err }
+}
+
+// Prefix iterates over the pairs of dbi with keys starting with prefix, as
+// Range does.  The keys with a prefix are consecutive in bytewise order, not
+// necessarily in the order of a table with another comparator, such as the
+// one of IntegerKey or ReverseKey.
+func (txn *Txn) Prefix(dbi DBI, prefix []byte) (iter.Seq2[[]byte, []byte], func() error) {
+	var err error
+	seq := func(yield func(k, v []byte) bool) {
+		err = txn.iterate(dbi, func(c *Cursor) error {
+			k, v, err := c.seek(Including(prefix), false)
+			for ; err == nil; k, v, err = c.Get(nil, nil, Next) {
+				if !bytes.HasPrefix(k, prefix) || !yield(k, v) {
+					return nil
+				}
+			}
+			return err
+		})
+	}
+	return seq, func() error { return err }
+}
+
+// Dups iterates over the values of key in a DupSort table, in order, moving c
+// along.  Unlike Txn.Range, the iterator uses c as it is, which must stay
+// open.  A missing key makes an empty iteration, and the key of a table
+// without DupSort an iteration over its one value.
+func (c *Cursor) Dups(key []byte) (iter.Seq2[[]byte, []byte], func() error) {
+	var err error
+	seq := func(yield func(k, v []byte) bool) {
+		k, v, e := c.Get(key, nil, SetKey)
+		for ; e == nil; k, v, e = c.Get(nil, nil, NextDup) {
+			if !yield(k, v) {
+				break
+			}
+		}
+		if IsNotFound(e) {
+			e = nil
+		}
+		err = e
+	}
+	return seq, func() error { return err }
+}
+
+// iterate runs fn with a pooled cursor of dbi, taking NotFound for the end of
+// the iteration.
+func (txn *Txn) iterate(dbi DBI, fn func(c *Cursor) error) error {
+	c := CursorFromPool()
+	defer CursorToPool(c)
+	if err := c.Bind(txn, dbi); err != nil {
+		return err
+	}
+	if err := fn(c); !IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// keyCmp returns the function comparing the keys of dbi with the bound b:
+// bytes.Compare for the bytewise order, or else Txn.Cmp.
+func (txn *Txn) keyCmp(dbi DBI, b Bound) func(x, y []byte) int {
+	if !b.set {
+		return nil
+	}
+	flags, err := txn.Flags(dbi)
+	if err == nil && flags&(ReverseKey|IntegerKey) == 0 && !txn.env.cmps.keyCmp(dbi) {
+		return bytes.Compare
+	}
+	return func(x, y []byte) int { return txn.Cmp(dbi, x, y) }
+}
+
+// seek moves c to the first pair within the lower bound b of a range, or with
+// last set to the last pair within the upper bound b.
+func (c *Cursor) seek(b Bound, last bool) (key, val []byte, err error) {
+	switch {
+	case !b.set && last:
+		return c.Get(nil, nil, Last)
+	case !b.set:
+		return c.Get(nil, nil, First)
+	}
+	k, v, err := c.Get(b.key, nil, SetRange)
+	if last {
+		switch {
+		case IsNotFound(err):
+			return c.Get(nil, nil, Last)
+		case err != nil:
+			return nil, nil, err
+		case b.excl || c.txn.Cmp(c.DBI(), k, b.key) != 0:
+			return c.Get(nil, nil, Prev)
+		}
+		// the last value of the key is the one before the next key.
+		if _, _, err = c.Get(nil, nil, NextNoDup); IsNotFound(err) {
+			return c.Get(nil, nil, Last)
+		} else if err != nil {
+			return nil, nil, err
+		}
+		return c.Get(nil, nil, Prev)
+	}
+	if err == nil && b.excl && c.txn.Cmp(c.DBI(), k, b.key) == 0 {
+		return c.Get(nil, nil, NextNoDup)
+	}
+	return k, v, err
+}
+
+// admits reports whether a key is within b, given c comparing it to b.key,
+// or b.key to it for an upper bound.
+func (b Bound) admits(c int) bool {
+	return c > 0 || c == 0 && !b.excl
+}
@@ -0,0 +1,305 @@
+package mdbx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"iter"
+	"slices"
+	"testing"
+)
+
+func collectKeys(seq iter.Seq2[[]byte, []byte]) []string {
+	var got []string
+	for k, v := range seq {
+		got = append(got, string(k)+"="+string(v))
+	}
+	return got
+}
+
+func TestTxn_Range(t *testing.T) {
+	env, _ := setup(t)
+	// the even keys 0 to 18, so that odd bounds fall between them.
+	db := mustOpenUniqueDB(t, env, "range")
+	err := env.Update(func(txn *Txn) error {
+		for i := uint32(0); i < 20; i += 2 {
+			if err := txn.Put(db, beKey(i), beKey(i), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bounds := []Bound{{}}
+	for i := uint32(0); i <= 20; i++ {
+		bounds = append(bounds, Including(beKey(i)), Excluding(beKey(i)))
+	}
+	within := func(i uint32, from, to Bound) bool {
+		if from.set {
+			f := binary.BigEndian.Uint32(from.key)
+			if i < f || i == f && from.excl {
+				return false
+			}
+		}
+		if to.set {
+			l := binary.BigEndian.Uint32(to.key)
+			if i > l || i == l && to.excl {
+				return false
+			}
+		}
+		return true
+	}
+	name := func(b Bound) string {
+		switch {
+		case !b.set:
+			return "open"
+		case b.excl:
+			return fmt.Sprintf("excluding %d", binary.BigEndian.Uint32(b.key))
+		}
+		return fmt.Sprintf("including %d", binary.BigEndian.Uint32(b.key))
+	}
+
+	err = env.View(func(txn *Txn) error {
+		for _, from := range bounds {
+			for _, to := range bounds {
+				var expect []uint32
+				for i := uint32(0); i < 20; i += 2 {
+					if within(i, from, to) {
+						expect = append(expect, i)
+					}
+				}
+				for _, reverse := range []bool{false, true} {
+					seq, errf := txn.Range(db, from, to)
+					if reverse {
+						seq, errf = txn.Reverse(db, from, to)
+					}
+					var got []uint32
+					for k, v := range seq {
+						if !slices.Equal(k, v) {
+							t.Errorf("unexpected value %x of key %x", v, k)
+						}
+						got = append(got, binary.BigEndian.Uint32(k))
+					}
+					if err := errf(); err != nil {
+						return err
+					}
+					if reverse {
+						slices.Reverse(got)
+					}
+					if !slices.Equal(got, expect) {
+						t.Errorf("%s to %s, reverse %t: got %v, expected %v", name(from), name(to), reverse, got, expect)
+					}
+				}
+			}
+		}
+
+		// breaking off the loop is no error.
+		seq, errf := txn.Range(db, Bound{}, Bound{})
+		for range seq {
+			break
+		}
+		if err := errf(); err != nil {
+			t.Errorf("unexpected error after a break: %v", err)
+		}
+		seq, errf = txn.Range(db+100, Bound{}, Bound{})
+		for range seq {
+			t.Error("unexpected pair of a bad dbi")
+		}
+		if err := errf(); !IsErrno(err, BadDBI) {
+			t.Errorf("unexpected error for a bad dbi: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_Range_dupSort(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenDupSortDB(t, env, "range_dups")
+	err := env.Update(func(txn *Txn) error {
+		for _, k := range []string{"a", "b", "c"} {
+			for _, v := range []string{"3", "1", "2"} {
+				if err := txn.Put(db, []byte(k), []byte(v), 0); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		seq, _ := txn.Range(db, Excluding([]byte("a")), Including([]byte("b")))
+		if got := collectKeys(seq); !slices.Equal(got, []string{"b=1", "b=2", "b=3"}) {
+			t.Errorf("unexpected range %q", got)
+		}
+		seq, _ = txn.Reverse(db, Including([]byte("a")), Including([]byte("b")))
+		if got := collectKeys(seq); !slices.Equal(got, []string{"b=3", "b=2", "b=1", "a=3", "a=2", "a=1"}) {
+			t.Errorf("unexpected reverse range %q", got)
+		}
+		seq, _ = txn.Reverse(db, Excluding([]byte("a")), Excluding([]byte("c")))
+		if got := collectKeys(seq); !slices.Equal(got, []string{"b=3", "b=2", "b=1"}) {
+			t.Errorf("unexpected reverse range %q", got)
+		}
+		seq, _ = txn.Reverse(db, Bound{}, Including([]byte("c")))
+		if got := collectKeys(seq); len(got) != 9 || got[0] != "c=3" {
+			t.Errorf("unexpected reverse range %q", got)
+		}
+
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		seq, errf := cur.Dups([]byte("b"))
+		if got := collectKeys(seq); !slices.Equal(got, []string{"b=1", "b=2", "b=3"}) || errf() != nil {
+			t.Errorf("unexpected dups %q %v", got, errf())
+		}
+		seq, errf = cur.Dups([]byte("bb"))
+		if got := collectKeys(seq); len(got) != 0 || errf() != nil {
+			t.Errorf("unexpected dups of a missing key %q %v", got, errf())
+		}
+		// the cursor is left where the loop broke off.
+		seq, _ = cur.Dups([]byte("c"))
+		for _, v := range seq {
+			if string(v) == "2" {
+				break
+			}
+		}
+		if _, v, err := cur.Get(nil, nil, GetCurrent); err != nil || string(v) != "2" {
+			t.Errorf("unexpected cursor position %q %v", v, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_Prefix(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenUniqueDB(t, env, "prefix")
+	err := env.Update(func(txn *Txn) error {
+		for _, k := range []string{"a", "ab", "abc", "abd", "ac", "b"} {
+			if err := txn.Put(db, []byte(k), nil, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		for _, test := range []struct {
+			prefix string
+			expect []string
+		}{
+			{"ab", []string{"ab=", "abc=", "abd="}},
+			{"a", []string{"a=", "ab=", "abc=", "abd=", "ac="}},
+			{"abz", nil},
+			{"c", nil},
+			{"", []string{"a=", "ab=", "abc=", "abd=", "ac=", "b="}},
+		} {
+			seq, errf := txn.Prefix(db, []byte(test.prefix))
+			if got := collectKeys(seq); !slices.Equal(got, test.expect) || errf() != nil {
+				t.Errorf("prefix %q: got %q %v", test.prefix, got, errf())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_Range_integerKey(t *testing.T) {
+	env, _ := setup(t)
+	var db DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		if db, err = txn.OpenDBISimple("range_ints", Create|IntegerKey); err != nil {
+			return err
+		}
+		for _, k := range []uint64{1, 255, 256, 1 << 40} {
+			if err := txn.PutUint64(db, k, nil, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the bounds compare as integers, not bytewise.
+	err = env.View(func(txn *Txn) error {
+		from := binary.NativeEndian.AppendUint64(nil, 2)
+		to := binary.NativeEndian.AppendUint64(nil, 1<<40)
+		seq, errf := txn.Range(db, Including(from), Excluding(to))
+		var got []uint64
+		for k := range seq {
+			got = append(got, binary.NativeEndian.Uint64(k))
+		}
+		if !slices.Equal(got, []uint64{255, 256}) || errf() != nil {
+			t.Errorf("unexpected range %v %v", got, errf())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_Range_compare(t *testing.T) {
+	env, _ := setup(t)
+	var rev, desc DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		if rev, err = txn.OpenDBISimple("range_rev", Create|ReverseKey); err != nil {
+			return err
+		}
+		if desc, err = txn.OpenDBIWithCompare("range_desc", Create, CmpDescending, nil); err != nil {
+			return err
+		}
+		for _, k := range []string{"ab", "ba"} {
+			if err := txn.Put(rev, []byte(k), nil, 0); err != nil {
+				return err
+			}
+		}
+		for _, k := range []string{"a", "bb", "ccc", "dddd"} {
+			if err := txn.Put(desc, []byte(k), nil, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the bounds compare in the order of the tables, not bytewise.
+	err = env.View(func(txn *Txn) error {
+		seq, errf := txn.Range(rev, Bound{}, Excluding([]byte("ab")))
+		if got := collectKeys(seq); !slices.Equal(got, []string{"ba="}) || errf() != nil {
+			t.Errorf("unexpected range of ReverseKey %q %v", got, errf())
+		}
+		seq, errf = txn.Range(desc, Bound{}, Including([]byte("bb")))
+		if got := collectKeys(seq); !slices.Equal(got, []string{"dddd=", "ccc=", "bb="}) || errf() != nil {
+			t.Errorf("unexpected range of CmpDescending %q %v", got, errf())
+		}
+		seq, errf = txn.Reverse(desc, Excluding([]byte("bb")), Bound{})
+		if got := collectKeys(seq); !slices.Equal(got, []string{"a="}) || errf() != nil {
+			t.Errorf("unexpected reverse of CmpDescending %q %v", got, errf())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
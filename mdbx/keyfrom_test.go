@@ -0,0 +1,127 @@
+package mdbx
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/erigontech/mdbx-go/mdbx/keys"
+)
+
+// FuzzKeys_libmdbx checks the keys package against the value-to-key and
+// key-to-value functions of libmdbx, taking the bits of u as the value.
+func FuzzKeys_libmdbx(f *testing.F) {
+	for _, v := range []uint64{0, 1, 1 << 31, 1 << 63, 1<<64 - 1, math.Float64bits(-1.5), math.Float64bits(math.Inf(-1)), math.Float64bits(math.NaN())} {
+		f.Add(v)
+	}
+	f.Fuzz(func(t *testing.T, u uint64) {
+		i64, i32 := int64(u), int32(u)
+		f64, f32 := math.Float64frombits(u), math.Float32frombits(uint32(u))
+
+		k64 := keyFromInt64(i64)
+		if k := keys.Int64Bits(i64); k != k64 {
+			t.Errorf("int64 %d: key %x, libmdbx %x", i64, k, k64)
+		}
+		if v := keys.Int64FromBits(k64); v != i64 || int64FromKey(binary.NativeEndian.AppendUint64(nil, k64)) != i64 {
+			t.Errorf("int64 %d: decoded as %d", i64, v)
+		}
+		k32 := keyFromInt32(i32)
+		if k := keys.Int32Bits(i32); k != k32 {
+			t.Errorf("int32 %d: key %x, libmdbx %x", i32, k, k32)
+		}
+		if v := keys.Int32FromBits(k32); v != i32 || int32FromKey(binary.NativeEndian.AppendUint32(nil, k32)) != i32 {
+			t.Errorf("int32 %d: decoded as %d", i32, v)
+		}
+
+		k64 = keyFromFloat64(f64)
+		if k := keys.Float64Bits(f64); k != k64 {
+			t.Errorf("float64 %x: key %x, libmdbx %x", u, k, k64)
+		}
+		v64 := float64FromKey(binary.NativeEndian.AppendUint64(nil, k64))
+		if v := keys.Float64FromBits(k64); math.Float64bits(v) != u || math.Float64bits(v64) != u {
+			t.Errorf("float64 %x: decoded as %x", u, math.Float64bits(v))
+		}
+		k32 = keyFromFloat32(f32)
+		if k := keys.Float32Bits(f32); k != k32 {
+			t.Errorf("float32 %x: key %x, libmdbx %x", uint32(u), k, k32)
+		}
+		v32 := float32FromKey(binary.NativeEndian.AppendUint32(nil, k32))
+		if v := keys.Float32FromBits(k32); math.Float32bits(v) != uint32(u) || math.Float32bits(v32) != uint32(u) {
+			t.Errorf("float32 %x: decoded as %x", uint32(u), math.Float32bits(v))
+		}
+	})
+}
+
+func TestKeys_order(t *testing.T) {
+	env, _ := setup(t)
+	vals := []float64{3, -0.5, math.Inf(1), 0, -1e300, 1e-300, math.Inf(-1), -7, 42}
+	var db, intDB DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		if db, err = txn.OpenDBISimple("floats", Create); err != nil {
+			return err
+		}
+		if intDB, err = txn.OpenDBISimple("intfloats", Create|IntegerKey); err != nil {
+			return err
+		}
+		for _, v := range vals {
+			if err := txn.Put(db, keys.AppendFloat64(nil, v), nil, 0); err != nil {
+				return err
+			}
+			if err := txn.PutUint64(intDB, keys.Float64Bits(v), nil, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := slices.Sorted(slices.Values(vals))
+	err = env.View(func(txn *Txn) error {
+		for _, test := range []struct {
+			dbi    DBI
+			decode func(k []byte) float64
+		}{
+			{db, keys.Float64},
+			{intDB, func(k []byte) float64 { return keys.Float64FromBits(binary.NativeEndian.Uint64(k)) }},
+		} {
+			cur, err := txn.OpenCursor(test.dbi)
+			if err != nil {
+				return err
+			}
+			var got []float64
+			for op := uint(First); ; op = Next {
+				k, _, err := cur.Get(nil, nil, op)
+				if IsNotFound(err) {
+					break
+				}
+				if err != nil {
+					cur.Close()
+					return err
+				}
+				got = append(got, test.decode(k))
+			}
+			cur.Close()
+			if !slices.Equal(got, expect) {
+				t.Errorf("table %d: unexpected order %v", test.dbi, got)
+			}
+		}
+
+		// a bound between the values seeks to the next one.
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		k, _, err := cur.Get(keys.AppendFloat64(nil, -1), nil, SetRange)
+		if err != nil || keys.Float64(k) != -0.5 {
+			t.Errorf("unexpected SetRange result %x %v", k, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
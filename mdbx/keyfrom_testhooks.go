@@ -0,0 +1,45 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+// The value-to-key and key-to-value functions of libmdbx, which the keys
+// package implements in Go, and is tested against.  The keys are native-endian
+// as in IntegerKey tables.
+//
+// These are test hooks, used only by keyfrom_test.go.  They live in a non-test
+// file because cgo cannot be used in _test.go files.
+
+func keyFromInt64(v int64) uint64 {
+	return uint64(C.mdbx_key_from_int64(C.int64_t(v)))
+}
+
+func keyFromInt32(v int32) uint32 {
+	return uint32(C.mdbx_key_from_int32(C.int32_t(v)))
+}
+
+func keyFromFloat64(f float64) uint64 {
+	return uint64(C.mdbx_key_from_double(C.double(f)))
+}
+
+func keyFromFloat32(f float32) uint32 {
+	return uint32(C.mdbx_key_from_float(C.float(f)))
+}
+
+func int64FromKey(key []byte) int64 {
+	return int64(C.mdbx_int64_from_key(*wrapVal(key)))
+}
+
+func int32FromKey(key []byte) int32 {
+	return int32(C.mdbx_int32_from_key(*wrapVal(key)))
+}
+
+func float64FromKey(key []byte) float64 {
+	return float64(C.mdbx_double_from_key(*wrapVal(key)))
+}
+
+func float32FromKey(key []byte) float32 {
+	return float32(C.mdbx_float_from_key(*wrapVal(key)))
+}
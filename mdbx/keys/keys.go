@@ -0,0 +1,175 @@
+/*
+Package keys encodes values as keys that order like the values under the
+default, bytewise, comparator of libmdbx.  The encodings are those of the
+value-to-key functions of libmdbx, such as mdbx_key_from_int64 and
+mdbx_key_from_double, written big-endian, so signed and floating-point numbers
+sort by value without a custom comparator.
+
+The Bits functions, such as Int64Bits, map a value to the unsigned integer of
+its libmdbx key, which is the key of an IntegerKey table, see
+mdbx.Txn.PutUint64.  The FromBits functions map it back, like the key-to-value
+functions of libmdbx, such as mdbx_int64_from_key.
+
+	k := keys.AppendFloat64(buf[:0], -1.5)
+	err := txn.Put(dbi, k, val, 0)
+
+Numbers and times encode to a fixed size, and Bytes and String fields are
+terminated, so a key composed of several fields by appending them in turn
+orders by the first field, then by the second, and so on.  A Reader decodes
+such a tuple.
+
+	k := keys.AppendInt64(buf[:0], account)
+	k = keys.AppendString(k, name)
+	k = keys.AppendTime(k, created)
+
+The Append functions append to dst without allocating when it has room, and
+none of the functions keep references to their arguments.
+*/
+package keys
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+const (
+	sign64 = 1 << 63
+	sign32 = 1 << 31
+)
+
+// Int64Bits returns the key of v, like mdbx_key_from_int64.  The keys order
+// as unsigned integers like the values they encode.
+func Int64Bits(v int64) uint64 {
+	return uint64(v) + sign64
+}
+
+// Int64FromBits returns the value of the key u of Int64Bits, like
+// mdbx_int64_from_key.
+func Int64FromBits(u uint64) int64 {
+	return int64(u - sign64)
+}
+
+// Int32Bits returns the key of v, like mdbx_key_from_int32.  The keys order
+// as unsigned integers like the values they encode.
+func Int32Bits(v int32) uint32 {
+	return uint32(v) + sign32
+}
+
+// Int32FromBits returns the value of the key u of Int32Bits, like
+// mdbx_int32_from_key.
+func Int32FromBits(u uint32) int32 {
+	return int32(u - sign32)
+}
+
+// Float64Bits returns the key of f, like mdbx_key_from_double.  The keys order
+// as unsigned integers like the values they encode, with -0 before +0, and
+// NaNs before -Inf or after +Inf, by sign.
+func Float64Bits(f float64) uint64 {
+	u := math.Float64bits(f)
+	if u&sign64 != 0 {
+		return ^u
+	}
+	return u | sign64
+}
+
+// Float64FromBits returns the value of the key u of Float64Bits, like
+// mdbx_double_from_key.
+func Float64FromBits(u uint64) float64 {
+	if u&sign64 != 0 {
+		return math.Float64frombits(u &^ sign64)
+	}
+	return math.Float64frombits(^u)
+}
+
+// Float32Bits returns the key of f, like mdbx_key_from_float.  The keys order
+// as Float64Bits does.
+func Float32Bits(f float32) uint32 {
+	u := math.Float32bits(f)
+	if u&sign32 != 0 {
+		return ^u
+	}
+	return u | sign32
+}
+
+// Float32FromBits returns the value of the key u of Float32Bits, like
+// mdbx_float_from_key.
+func Float32FromBits(u uint32) float32 {
+	if u&sign32 != 0 {
+		return math.Float32frombits(u &^ sign32)
+	}
+	return math.Float32frombits(^u)
+}
+
+// The decoders of fixed-size fields, such as Uint64, read the start of a key,
+// and panic if it is too short, like binary.BigEndian.Uint64.  A Reader
+// decodes the fields of a tuple in turn, and fails rather than panic.
+
+// AppendUint64 appends the 8-byte key of v, which is v big-endian.
+func AppendUint64(dst []byte, v uint64) []byte {
+	return binary.BigEndian.AppendUint64(dst, v)
+}
+
+// Uint64 decodes the key of AppendUint64.
+func Uint64(key []byte) uint64 {
+	return binary.BigEndian.Uint64(key)
+}
+
+// AppendInt64 appends the 8-byte key of v, which is Int64Bits(v) big-endian.
+func AppendInt64(dst []byte, v int64) []byte {
+	return binary.BigEndian.AppendUint64(dst, Int64Bits(v))
+}
+
+// Int64 decodes the key of AppendInt64.
+func Int64(key []byte) int64 {
+	return Int64FromBits(binary.BigEndian.Uint64(key))
+}
+
+// AppendInt32 appends the 4-byte key of v, which is Int32Bits(v) big-endian.
+func AppendInt32(dst []byte, v int32) []byte {
+	return binary.BigEndian.AppendUint32(dst, Int32Bits(v))
+}
+
+// Int32 decodes the key of AppendInt32.
+func Int32(key []byte) int32 {
+	return Int32FromBits(binary.BigEndian.Uint32(key))
+}
+
+// AppendFloat64 appends the 8-byte key of f, which is Float64Bits(f)
+// big-endian.
+func AppendFloat64(dst []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(dst, Float64Bits(f))
+}
+
+// Float64 decodes the key of AppendFloat64.
+func Float64(key []byte) float64 {
+	return Float64FromBits(binary.BigEndian.Uint64(key))
+}
+
+// AppendFloat32 appends the 4-byte key of f, which is Float32Bits(f)
+// big-endian.
+func AppendFloat32(dst []byte, f float32) []byte {
+	return binary.BigEndian.AppendUint32(dst, Float32Bits(f))
+}
+
+// Float32 decodes the key of AppendFloat32.
+func Float32(key []byte) float32 {
+	return Float32FromBits(binary.BigEndian.Uint32(key))
+}
+
+// TimeSize is the size of the key of AppendTime.
+const TimeSize = 12
+
+// AppendTime appends the key of t, which orders by instant: the Unix seconds
+// of t as AppendInt64 does, followed by the nanoseconds big-endian.  The
+// location and monotonic clock reading of t are not encoded.
+func AppendTime(dst []byte, t time.Time) []byte {
+	dst = AppendInt64(dst, t.Unix())
+	return binary.BigEndian.AppendUint32(dst, uint32(t.Nanosecond()))
+}
+
+// Time decodes the key of AppendTime, in UTC.
+func Time(key []byte) time.Time {
+	_ = key[TimeSize-1]
+	return time.Unix(Int64(key), int64(binary.BigEndian.Uint32(key[8:]))).UTC()
+}
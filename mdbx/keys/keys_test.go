@@ -0,0 +1,208 @@
+package keys
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func checkOrder(t *testing.T, name string, c int, a, b []byte) {
+	t.Helper()
+	if kc := bytes.Compare(a, b); kc != c {
+		t.Errorf("%s: keys %x and %x compare %d, values %d", name, a, b, kc, c)
+	}
+}
+
+func FuzzIntegers(f *testing.F) {
+	for _, v := range []uint64{0, 1, 1<<31 - 1, 1 << 31, 1<<32 - 1, 1<<63 - 1, 1 << 63, 1<<64 - 1} {
+		f.Add(v, uint64(0))
+		f.Add(v, v-1)
+	}
+	f.Fuzz(func(t *testing.T, a, b uint64) {
+		checkOrder(t, "uint64", cmp.Compare(a, b), AppendUint64(nil, a), AppendUint64(nil, b))
+		checkOrder(t, "int64", cmp.Compare(int64(a), int64(b)), AppendInt64(nil, int64(a)), AppendInt64(nil, int64(b)))
+		checkOrder(t, "int32", cmp.Compare(int32(a), int32(b)), AppendInt32(nil, int32(a)), AppendInt32(nil, int32(b)))
+		if v := Uint64(AppendUint64(nil, a)); v != a {
+			t.Errorf("uint64 %d decoded as %d", a, v)
+		}
+		if v := Int64(AppendInt64(nil, int64(a))); v != int64(a) {
+			t.Errorf("int64 %d decoded as %d", int64(a), v)
+		}
+		if v := Int32(AppendInt32(nil, int32(a))); v != int32(a) {
+			t.Errorf("int32 %d decoded as %d", int32(a), v)
+		}
+	})
+}
+
+// cmpFloat compares floats as their keys order: by value, with -0 before +0,
+// and NaNs by sign and payload beyond the infinities.
+func cmpFloat[F float32 | float64](a, b F, bits func(F) uint64) int {
+	if a == b && a != 0 || a != b && !math.IsNaN(float64(a)) && !math.IsNaN(float64(b)) {
+		return cmp.Compare(a, b)
+	}
+	// zeros and NaNs are told apart by sign, and NaNs by payload.
+	sa, sb := math.Signbit(float64(a)), math.Signbit(float64(b))
+	if sa != sb {
+		if sa {
+			return -1
+		}
+		return 1
+	}
+	if math.IsNaN(float64(a)) != math.IsNaN(float64(b)) {
+		if math.IsNaN(float64(a)) == sa {
+			return -1
+		}
+		return 1
+	}
+	c := cmp.Compare(bits(a), bits(b))
+	if sa {
+		return -c
+	}
+	return c
+}
+
+func FuzzFloats(f *testing.F) {
+	for _, v := range []float64{0, math.Copysign(0, -1), 1, -1, math.SmallestNonzeroFloat64, math.MaxFloat64, math.Inf(1), math.Inf(-1), math.NaN(), -math.NaN()} {
+		f.Add(math.Float64bits(v), math.Float64bits(0))
+		f.Add(math.Float64bits(v), math.Float64bits(-v))
+	}
+	f.Fuzz(func(t *testing.T, a, b uint64) {
+		fa, fb := math.Float64frombits(a), math.Float64frombits(b)
+		c := cmpFloat(fa, fb, math.Float64bits)
+		checkOrder(t, "float64", c, AppendFloat64(nil, fa), AppendFloat64(nil, fb))
+		if v := Float64(AppendFloat64(nil, fa)); math.Float64bits(v) != a {
+			t.Errorf("float64 %x decoded as %x", a, math.Float64bits(v))
+		}
+
+		ga, gb := math.Float32frombits(uint32(a)), math.Float32frombits(uint32(b))
+		c = cmpFloat(ga, gb, func(g float32) uint64 { return uint64(math.Float32bits(g)) })
+		checkOrder(t, "float32", c, AppendFloat32(nil, ga), AppendFloat32(nil, gb))
+		if v := Float32(AppendFloat32(nil, ga)); math.Float32bits(v) != uint32(a) {
+			t.Errorf("float32 %x decoded as %x", uint32(a), math.Float32bits(v))
+		}
+	})
+}
+
+func FuzzTime(f *testing.F) {
+	f.Add(int64(0), int64(0))
+	f.Add(int64(-1), int64(1))
+	f.Add(time.Time{}.UnixNano(), int64(math.MaxInt64))
+	f.Fuzz(func(t *testing.T, a, b int64) {
+		// the high bits are the seconds, short of the limits of time.Time,
+		// and the low bits the nanoseconds.
+		ta := time.Unix(a>>2, a&0x3fffffff%1e9)
+		tb := time.Unix(b>>2, b&0x3fffffff%1e9)
+		checkOrder(t, "time", ta.Compare(tb), AppendTime(nil, ta), AppendTime(nil, tb))
+		if v := Time(AppendTime(nil, ta)); !v.Equal(ta) || v.Location() != time.UTC {
+			t.Errorf("time %v decoded as %v", ta, v)
+		}
+	})
+}
+
+type tuple struct {
+	id   int64
+	name []byte
+	at   float64
+}
+
+func (tp tuple) key() []byte {
+	k := AppendInt64(nil, tp.id)
+	k = AppendBytes(k, tp.name)
+	return AppendFloat64(k, tp.at)
+}
+
+func (tp tuple) compare(other tuple) int {
+	if c := cmp.Compare(tp.id, other.id); c != 0 {
+		return c
+	}
+	if c := bytes.Compare(tp.name, other.name); c != 0 {
+		return c
+	}
+	return cmpFloat(tp.at, other.at, math.Float64bits)
+}
+
+func FuzzTuple(f *testing.F) {
+	f.Add(int64(1), []byte("a"), 0.5, int64(1), []byte("a\x00"), -0.5)
+	f.Add(int64(1), []byte("a\x00\x01"), 0.5, int64(1), []byte("a\x00\xff"), -0.5)
+	f.Add(int64(-1), []byte(""), 1.0, int64(-1), []byte("\x00"), 1.0)
+	f.Add(int64(0), []byte("ab"), 2.0, int64(0), []byte("a"), 3.0)
+	f.Fuzz(func(t *testing.T, ida int64, namea []byte, ata float64, idb int64, nameb []byte, atb float64) {
+		a, b := tuple{ida, namea, ata}, tuple{idb, nameb, atb}
+		ka, kb := a.key(), b.key()
+		checkOrder(t, "tuple", a.compare(b), ka, kb)
+
+		r := NewReader(ka)
+		id, name, at := r.Int64(), r.Bytes(nil), r.Float64()
+		if err := r.Err(); err != nil || r.Len() != 0 {
+			t.Fatalf("tuple %x: %v with %d bytes left", ka, err, r.Len())
+		}
+		if id != ida || !bytes.Equal(name, namea) || math.Float64bits(at) != math.Float64bits(ata) {
+			t.Errorf("tuple %v decoded as %d %q %v", a, id, name, at)
+		}
+		r = NewReader(ka)
+		r.Fixed(8)
+		r.Skip()
+		if at := r.Float64(); r.Err() != nil || math.Float64bits(at) != math.Float64bits(ata) {
+			t.Errorf("tuple %x: unexpected field %v after a skip: %v", ka, at, r.Err())
+		}
+	})
+}
+
+func TestReader_errors(t *testing.T) {
+	for _, test := range []struct {
+		key  []byte
+		read func(r *Reader)
+		err  error
+	}{
+		{[]byte{1, 2, 3}, func(r *Reader) { r.Int32() }, ErrShort},
+		{AppendInt64(nil, 1), func(r *Reader) { r.Int64(); r.Uint64() }, ErrShort},
+		{[]byte("abc"), func(r *Reader) { r.Bytes(nil) }, ErrMalformed},
+		{[]byte("abc\x00"), func(r *Reader) { r.Skip() }, ErrMalformed},
+		{[]byte("a\x00\x02"), func(r *Reader) { r.Bytes(nil) }, ErrMalformed},
+		{AppendString(nil, "a"), func(r *Reader) { r.Fixed(4) }, ErrShort},
+	} {
+		r := NewReader(test.key)
+		test.read(&r)
+		if !errors.Is(r.Err(), test.err) {
+			t.Errorf("key %x: unexpected error %v", test.key, r.Err())
+		}
+		// later fields read as zero values.
+		if v := r.Time(); !v.IsZero() || !errors.Is(r.Err(), test.err) || r.Len() != 0 {
+			t.Errorf("key %x: unexpected read after an error: %v %v", test.key, v, r.Err())
+		}
+	}
+}
+
+func TestAppend_noAllocs(t *testing.T) {
+	buf := make([]byte, 0, 64)
+	now := time.Now()
+	name := "a\x00b"
+	allocs := testing.AllocsPerRun(100, func() {
+		k := AppendInt64(buf[:0], -1)
+		k = AppendUint64(k, 1)
+		k = AppendInt32(k, -1)
+		k = AppendFloat64(k, -1.5)
+		k = AppendFloat32(k, 1.5)
+		k = AppendTime(k, now)
+		k = AppendString(k, name)
+		k = AppendFixed(k, []byte{1, 2})
+		r := NewReader(k)
+		r.Int64()
+		r.Uint64()
+		r.Int32()
+		r.Float64()
+		r.Float32()
+		r.Time()
+		r.Skip()
+		r.Fixed(2)
+		if r.Err() != nil {
+			panic(r.Err())
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("%v allocations", allocs)
+	}
+}
@@ -0,0 +1,181 @@
+package keys
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+// Bytes fields escape each zero byte as 0x00 0xff and end with 0x00 0x01, so
+// a field orders before any longer field it is a prefix of, and before the
+// fields appended after it are compared.
+const (
+	escape     = 0x00
+	escapedNul = 0xff
+	terminator = 0x01
+)
+
+var (
+	// ErrShort is the error of a Reader reading past the end of a key.
+	ErrShort = errors.New("keys: key too short")
+	// ErrMalformed is the error of a Reader reading a Bytes or String field
+	// which was not written by AppendBytes or AppendString.
+	ErrMalformed = errors.New("keys: malformed bytes field")
+)
+
+// AppendFixed appends b as it is, as a field of a size known to the reader,
+// such as a hash or the bytes of a [20]byte array.  The field orders bytewise
+// only against fields of the same size, see AppendBytes otherwise.
+func AppendFixed(dst, b []byte) []byte {
+	return append(dst, b...)
+}
+
+// AppendBytes appends b as a field of any size which orders bytewise, escaping
+// its zero bytes and terminating it.
+func AppendBytes(dst, b []byte) []byte {
+	return appendEscaped(dst, b)
+}
+
+// AppendString is AppendBytes for a string.
+func AppendString(dst []byte, s string) []byte {
+	return appendEscaped(dst, s)
+}
+
+func appendEscaped[T []byte | string](dst []byte, b T) []byte {
+	start := 0
+	for i := range len(b) {
+		if b[i] == escape {
+			dst = append(dst, b[start:i+1]...)
+			dst = append(dst, escapedNul)
+			start = i + 1
+		}
+	}
+	dst = append(dst, b[start:]...)
+	return append(dst, escape, terminator)
+}
+
+// A Reader decodes the fields of a tuple key in the order they were appended.
+// Once a field cannot be decoded, Err returns why, and the Reader returns zero
+// values from then on.  The fields are not checked to be of the type read.
+type Reader struct {
+	key []byte
+	err error
+}
+
+// NewReader returns a Reader of the fields of key.
+func NewReader(key []byte) Reader {
+	return Reader{key: key}
+}
+
+// Err returns the first error of r, or nil.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Len returns the number of bytes of the key left to read.
+func (r *Reader) Len() int {
+	return len(r.key)
+}
+
+func (r *Reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.key) {
+		r.err = ErrShort
+		r.key = nil
+		return nil
+	}
+	b := r.key[:n:n]
+	r.key = r.key[n:]
+	return b
+}
+
+// Uint64 reads a field of AppendUint64.
+func (r *Reader) Uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return Uint64(b)
+	}
+	return 0
+}
+
+// Int64 reads a field of AppendInt64.
+func (r *Reader) Int64() int64 {
+	if b := r.next(8); b != nil {
+		return Int64(b)
+	}
+	return 0
+}
+
+// Int32 reads a field of AppendInt32.
+func (r *Reader) Int32() int32 {
+	if b := r.next(4); b != nil {
+		return Int32(b)
+	}
+	return 0
+}
+
+// Float64 reads a field of AppendFloat64.
+func (r *Reader) Float64() float64 {
+	if b := r.next(8); b != nil {
+		return Float64(b)
+	}
+	return 0
+}
+
+// Float32 reads a field of AppendFloat32.
+func (r *Reader) Float32() float32 {
+	if b := r.next(4); b != nil {
+		return Float32(b)
+	}
+	return 0
+}
+
+// Time reads a field of AppendTime.
+func (r *Reader) Time() time.Time {
+	if b := r.next(TimeSize); b != nil {
+		return Time(b)
+	}
+	return time.Time{}
+}
+
+// Fixed reads a field of AppendFixed of n bytes.  The field is a view into
+// the key.
+func (r *Reader) Fixed(n int) []byte {
+	return r.next(n)
+}
+
+// Bytes reads a field of AppendBytes or AppendString, appending it to dst.
+func (r *Reader) Bytes(dst []byte) []byte {
+	return r.unescape(dst, true)
+}
+
+// Skip skips a field of AppendBytes or AppendString.
+func (r *Reader) Skip() {
+	r.unescape(nil, false)
+}
+
+func (r *Reader) unescape(dst []byte, keep bool) []byte {
+	if r.err != nil {
+		return dst
+	}
+	for b := r.key; ; {
+		i := bytes.IndexByte(b, escape)
+		if i < 0 || i+1 == len(b) || b[i+1] != escapedNul && b[i+1] != terminator {
+			r.err = ErrMalformed
+			r.key = nil
+			return dst
+		}
+		if b[i+1] == terminator {
+			if keep {
+				dst = append(dst, b[:i]...)
+			}
+			r.key = b[i+2:]
+			return dst
+		}
+		if keep {
+			dst = append(dst, b[:i+1]...)
+		}
+		b = b[i+2:]
+	}
+}
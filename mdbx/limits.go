@@ -0,0 +1,81 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+// EnvLimits are the size limits of a database with a given page size, and of
+// the records of a table with given flags in it.  Keys, values and pairs over
+// the page limits are stored in large pages of their own, which is slower
+// and wastes space.
+type EnvLimits struct {
+	PageSize   int   // The page size the limits are for.
+	MinDBSize  int64 // Minimal size of the database.
+	MaxDBSize  int64 // Maximal size of the database.
+	MaxTxnSize int64 // Maximal size of the dirty pages of a write transaction.
+
+	MinKeySize int // Minimal size of a key, non-zero for IntegerKey.
+	MaxKeySize int // Maximal size of a key.
+	MinValSize int // Minimal size of a value, non-zero for IntegerDup.
+	MaxValSize int // Maximal size of a value.
+
+	// MaxPairSizeOnPage is the maximal size of a key-value pair that fits in
+	// a leaf page.
+	MaxPairSizeOnPage int
+	// MaxValSizeOnPage is the maximal size of a value that fits in a leaf
+	// page, or in a single large page.
+	MaxValSizeOnPage int
+}
+
+// Limits returns the limits of a database with the given page size for a
+// table opened with dbFlags, such as DupSort.  A pageSize of 0 is the default
+// page size.  Limits fails with EINVAL unless pageSize is a power of two
+// between MinPageSize and MaxPageSize.
+//
+// See mdbx_limits_dbsize_max, mdbx_limits_keysize_max,
+// mdbx_limits_valsize_max, mdbx_limits_pairsize4page_max and the like.
+func Limits(pageSize int, dbFlags uint) (*EnvLimits, error) {
+	if pageSize == 0 {
+		pageSize = int(C.mdbx_default_pagesize())
+	}
+	ps, flags := C.intptr_t(pageSize), C.MDBX_db_flags_t(dbFlags)
+	lim := &EnvLimits{
+		PageSize:          pageSize,
+		MinDBSize:         int64(C.mdbx_limits_dbsize_min(ps)),
+		MaxDBSize:         int64(C.mdbx_limits_dbsize_max(ps)),
+		MaxTxnSize:        int64(C.mdbx_limits_txnsize_max(ps)),
+		MinKeySize:        int(C.mdbx_limits_keysize_min(flags)),
+		MaxKeySize:        int(C.mdbx_limits_keysize_max(ps, flags)),
+		MinValSize:        int(C.mdbx_limits_valsize_min(flags)),
+		MaxValSize:        int(C.mdbx_limits_valsize_max(ps, flags)),
+		MaxPairSizeOnPage: int(C.mdbx_limits_pairsize4page_max(ps, flags)),
+		MaxValSizeOnPage:  int(C.mdbx_limits_valsize4page_max(ps, flags)),
+	}
+	if pageSize < 0 || lim.MaxDBSize < 0 || lim.MaxKeySize < 0 {
+		return nil, operrno("mdbx_limits_dbsize_max", C.MDBX_EINVAL)
+	}
+	return lim, nil
+}
+
+// Limits returns the limits of env for a table opened with dbFlags.  Before
+// Open they are for the page size set by SetGeometry, or the default one.
+//
+// See mdbx_env_get_maxkeysize_ex, mdbx_env_get_maxvalsize_ex,
+// mdbx_env_get_pairsize4page_max and mdbx_env_get_valsize4page_max.
+func (env *Env) Limits(dbFlags uint) (*EnvLimits, error) {
+	info, err := env.Info(nil)
+	if err != nil {
+		return nil, err
+	}
+	lim, err := Limits(int(info.PageSize), dbFlags)
+	if err != nil {
+		return nil, err
+	}
+	flags := C.MDBX_db_flags_t(dbFlags)
+	lim.MaxKeySize = int(C.mdbx_env_get_maxkeysize_ex(env._env, flags))
+	lim.MaxValSize = int(C.mdbx_env_get_maxvalsize_ex(env._env, flags))
+	lim.MaxPairSizeOnPage = int(C.mdbx_env_get_pairsize4page_max(env._env, flags))
+	lim.MaxValSizeOnPage = int(C.mdbx_env_get_valsize4page_max(env._env, flags))
+	return lim, nil
+}
@@ -0,0 +1,96 @@
+package mdbx
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	var prev *EnvLimits
+	for ps := MinPageSize; ps <= MaxPageSize; ps *= 2 {
+		lim, err := Limits(ps, 0)
+		if err != nil {
+			t.Fatalf("page size %d: %v", ps, err)
+		}
+		if lim.PageSize != ps || lim.MinDBSize <= 0 || lim.MaxTxnSize <= 0 || lim.MinKeySize != 0 {
+			t.Errorf("page size %d: unexpected limits %+v", ps, lim)
+		}
+		if lim.MaxKeySize >= lim.MaxPairSizeOnPage || lim.MaxPairSizeOnPage >= ps || lim.MaxValSizeOnPage >= lim.MaxValSize {
+			t.Errorf("page size %d: inconsistent limits %+v", ps, lim)
+		}
+		if prev != nil && (lim.MaxKeySize <= prev.MaxKeySize || lim.MaxDBSize < prev.MaxDBSize) {
+			t.Errorf("page size %d: limits %+v not above %+v", ps, lim, prev)
+		}
+		prev = lim
+
+		dups, err := Limits(ps, DupSort)
+		if err != nil {
+			t.Fatalf("page size %d: %v", ps, err)
+		}
+		// the values of DupSort tables are keys of their nested trees.
+		if dups.MaxValSize >= lim.MaxValSize || dups.MaxValSize > dups.MaxPairSizeOnPage {
+			t.Errorf("page size %d: unexpected DupSort limits %+v", ps, dups)
+		}
+	}
+
+	lim, err := Limits(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lim.PageSize < MinPageSize || lim.PageSize > MaxPageSize {
+		t.Errorf("unexpected default page size %d", lim.PageSize)
+	}
+
+	ints, err := Limits(0, IntegerKey|DupSort|DupFixed|IntegerDup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ints.MinKeySize != 4 || ints.MaxKeySize != 8 || ints.MinValSize != 4 || ints.MaxValSize != 8 {
+		t.Errorf("unexpected integer limits %+v", ints)
+	}
+
+	for _, ps := range []int{-1, MinPageSize / 2, MinPageSize + 1, MaxPageSize * 2} {
+		if lim, err := Limits(ps, 0); !IsErrnoSys(err, syscall.EINVAL) {
+			t.Errorf("page size %d: unexpected result %+v %v", ps, lim, err)
+		}
+	}
+}
+
+func TestEnv_Limits(t *testing.T) {
+	env, _ := setup(t)
+	for _, flags := range []uint{0, DupSort, DupSort | DupFixed} {
+		lim, err := env.Limits(flags)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// setup opens env with 4KiB pages.
+		expect, err := Limits(4096, flags)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *lim != *expect {
+			t.Errorf("flags %#x: env limits %+v differ from %+v", flags, lim, expect)
+		}
+	}
+
+	lim, err := env.Limits(DupSort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := mustOpenDupSortDB(t, env, "dups")
+	err = env.Update(func(txn *Txn) error {
+		if err := txn.Put(db, []byte("k"), make([]byte, lim.MaxValSize), 0); err != nil {
+			return err
+		}
+		if err := txn.Put(db, []byte("k"), make([]byte, lim.MaxValSize+1), 0); !IsErrno(err, BadValSize) {
+			t.Errorf("unexpected error for an oversized value: %v", err)
+		}
+		if err := txn.Put(db, make([]byte, lim.MaxKeySize+1), []byte("v"), 0); !IsErrno(err, BadValSize) {
+			t.Errorf("unexpected error for an oversized key: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,115 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// LevelFatal is the slog level of libmdbx records logged with LogLvlFatal.
+const LevelFatal = slog.LevelError + 4
+
+// LoggerOptions configures SetLogger.
+type LoggerOptions struct {
+	// Level is the most verbose LogLvl* level libmdbx produces records for,
+	// e.g. LogLvlWarn, or LogLvlDoNotChange to keep the current one.  Unless
+	// libmdbx is built with MDBX_DEBUG, levels beyond LogLvlNotice are
+	// lowered to it.
+	Level LogLvl
+	// Debug is a combination of the Dbg* flags, or DbgDoNotChange to keep
+	// the current ones.  DbgAssert and DbgAudit take effect only in builds
+	// with MDBX_DEBUG or MDBX_FORCE_ASSERTIONS.
+	Debug int
+	// Label, if not empty, is attached to every record as the "label"
+	// attribute, typically the Label of the Env being diagnosed.
+	Label Label
+}
+
+type logger struct {
+	handler slog.Handler
+}
+
+var currentLogger atomic.Pointer[logger]
+
+// SetLogger routes the log records of libmdbx into h.  The libmdbx levels
+// are mapped to slog levels as follows:
+//
+//	LogLvlFatal                 LevelFatal
+//	LogLvlError                 slog.LevelError
+//	LogLvlWarn                  slog.LevelWarn
+//	LogLvlNotice                slog.LevelInfo + 2
+//	LogLvlVerbose               slog.LevelInfo
+//	LogLvlDebug                 slog.LevelDebug
+//	LogLvlTrace, LogLvlExtra    slog.LevelDebug - 4, - 8
+//
+// Every record carries the "func" and "line" attributes naming the libmdbx
+// source of the message.  A nil h restores the default logger of libmdbx,
+// which prints to stderr.
+//
+// Logging is global to the process, like the level and the debug flags set
+// along, and replaces the configuration made by Env.SetDebug.  Records are
+// passed to h while libmdbx holds its logging lock, so h must not call back
+// into this package.
+//
+// See mdbx_setup_debug_nofmt.
+func SetLogger(h slog.Handler, opts LoggerOptions) error {
+	if h != nil && opts.Label != "" {
+		h = h.WithAttrs([]slog.Attr{slog.String("label", string(opts.Label))})
+	}
+	var l *logger
+	if h != nil {
+		l = &logger{handler: h}
+	}
+	prev := currentLogger.Swap(l)
+	ret := C.mdbxgo_setup_logger(opts.Level, C.MDBX_debug_flags_t(opts.Debug), C.bool(h != nil))
+	if ret < 0 {
+		currentLogger.CompareAndSwap(l, prev)
+		return operrno("mdbx_setup_debug", C.MDBX_EINVAL)
+	}
+	return nil
+}
+
+// slogLevel maps a libmdbx log level to an slog level.  See SetLogger.
+func slogLevel(lvl LogLvl) slog.Level {
+	switch lvl {
+	case LogLvlFatal:
+		return LevelFatal
+	case LogLvlError:
+		return slog.LevelError
+	case LogLvlWarn:
+		return slog.LevelWarn
+	case LogLvlNotice:
+		return slog.LevelInfo + 2
+	case LogLvlVerbose:
+		return slog.LevelInfo
+	case LogLvlDebug:
+		return slog.LevelDebug
+	case LogLvlTrace:
+		return slog.LevelDebug - 4
+	default:
+		return slog.LevelDebug - 8
+	}
+}
+
+func (l *logger) log(lvl LogLvl, function string, line int, msg string) {
+	level := slogLevel(lvl)
+	ctx := context.Background()
+	if !l.handler.Enabled(ctx, level) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), level, strings.TrimRight(msg, "\n"), 0)
+	if function != "" {
+		r.AddAttrs(slog.String("func", function))
+	}
+	if line > 0 {
+		r.AddAttrs(slog.Int("line", line))
+	}
+	_ = l.handler.Handle(ctx, r)
+}
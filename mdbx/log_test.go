@@ -0,0 +1,101 @@
+package mdbx
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetLogger(t *testing.T) {
+	h := &recordHandler{}
+	err := SetLogger(h, LoggerOptions{Level: LogLvlNotice, Debug: DbgDoNotChange, Label: "test-label"})
+	if err != nil {
+		t.Fatalf("set logger: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := SetLogger(nil, LoggerOptions{Level: LogLvlNotice, Debug: DbgDoNotChange}); err != nil {
+			t.Errorf("restore logger: %v", err)
+		}
+	})
+
+	// opening a new database notices the empty file.
+	setup(t)
+
+	records := h.records()
+	if len(records) == 0 {
+		t.Fatalf("nothing logged")
+	}
+	for _, r := range records {
+		if r.Level > slog.LevelInfo+2 || strings.HasSuffix(r.Message, "\n") {
+			t.Errorf("unexpected record: %s %q", r.Level, r.Message)
+		}
+		attrs := map[string]string{}
+		r.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value.String()
+			return true
+		})
+		if attrs["label"] != "test-label" || attrs["func"] == "" || attrs["line"] == "" {
+			t.Errorf("unexpected attributes of %q: %v", r.Message, attrs)
+		}
+	}
+}
+
+func TestSetLogger_level(t *testing.T) {
+	for lvl, want := range map[LogLvl]slog.Level{
+		LogLvlFatal:   LevelFatal,
+		LogLvlError:   slog.LevelError,
+		LogLvlWarn:    slog.LevelWarn,
+		LogLvlNotice:  slog.LevelInfo + 2,
+		LogLvlVerbose: slog.LevelInfo,
+		LogLvlDebug:   slog.LevelDebug,
+		LogLvlTrace:   slog.LevelDebug - 4,
+		LogLvlExtra:   slog.LevelDebug - 8,
+	} {
+		if got := slogLevel(lvl); got != want {
+			t.Errorf("level %d: %s (!= %s)", lvl, got, want)
+		}
+	}
+}
+
+// recordHandler collects the records it handles, including the attributes
+// it was given.
+type recordHandler struct {
+	mu    *sync.Mutex
+	attrs []slog.Attr
+	recs  *[]slog.Record
+}
+
+func (h *recordHandler) init() {
+	if h.mu == nil {
+		h.mu = &sync.Mutex{}
+		h.recs = &[]slog.Record{}
+	}
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.init()
+	r = r.Clone()
+	r.AddAttrs(h.attrs...)
+	h.mu.Lock()
+	*h.recs = append(*h.recs, r)
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *recordHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h.init()
+	return &recordHandler{mu: h.mu, recs: h.recs, attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)}
+}
+
+func (h *recordHandler) WithGroup(string) slog.Handler { return h }
+
+func (h *recordHandler) records() []slog.Record {
+	h.init()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]slog.Record(nil), *h.recs...)
+}
@@ -1,6 +1,7 @@
 /* lmdbgo.c
  * Helper utilities for github.com/bmatsuo/lmdb-go/lmdb
  * */
+#include <stdlib.h>
 #include <string.h>
 #include <stdio.h>
 #include "_cgo_export.h"
@@ -15,6 +16,20 @@
         (r).vbase = (val).iov_base; (r).vlen = (val).iov_len; \
     } while (0)
 
+unsigned mdbxgo_forks;
+
+#ifndef _WIN32
+#include <pthread.h>
+
+static void mdbxgo_fork_child(void) {
+    mdbxgo_forks++;
+}
+
+int mdbxgo_install_fork_guard(void) {
+    return pthread_atfork(NULL, NULL, &mdbxgo_fork_child);
+}
+#endif
+
 uint64_t mdbxgo_tid_to_u64(mdbx_tid_t tid) {
     return (uint64_t)(uintptr_t)tid;
 }
@@ -55,6 +70,25 @@ int mdbxgo_get(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, MDBX_val *va
     return mdbx_get(txn, dbi, &key, val);
 }
 
+mdbxgo_get_ex_result mdbxgo_get_ex(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn) {
+    mdbxgo_get_ex_result r = {0};
+    MDBX_val key, val = {0};
+    MDBXGO_SET_VAL(&key, kn, kdata);
+    r.err = mdbx_get_ex(txn, dbi, &key, &val, &r.count);
+    r.vbase = val.iov_base;
+    r.vlen = val.iov_len;
+    return r;
+}
+
+mdbxgo_val_result mdbxgo_get_equal_or_great(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn) {
+    mdbxgo_val_result r = {0};
+    MDBX_val key, val = {0};
+    MDBXGO_SET_VAL(&key, kn, kdata);
+    r.err = mdbx_get_equal_or_great(txn, dbi, &key, &val);
+    MDBXGO_SET_VAL_RESULT(r, key, val);
+    return r;
+}
+
 int mdbxgo_put2(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, char *vdata, size_t vn, MDBX_put_flags_t flags) {
     MDBX_val key, val;
     MDBXGO_SET_VAL(&key, kn, kdata);
@@ -107,6 +141,175 @@ mdbxgo_val_result mdbxgo_cursor_get_val(MDBX_cursor *cur, char *kdata, size_t kn
     return r;
 }
 
+/* mdbxgo_int holds an integer of an IntegerKey or IntegerDup table. */
+typedef union { uint32_t u32; uint64_t u64; } mdbxgo_int;
+
+static MDBX_val mdbxgo_int_val(mdbxgo_int *i, uint64_t v, size_t n) {
+    if (n == sizeof(uint32_t)) {
+        i->u32 = (uint32_t)v;
+    } else {
+        i->u64 = v;
+    }
+    return (MDBX_val){ .iov_len = n, .iov_base = i };
+}
+
+mdbxgo_val_result mdbxgo_get_int(MDBX_txn *txn, MDBX_dbi dbi, uint64_t k, size_t kn) {
+    mdbxgo_val_result r = {0};
+    mdbxgo_int ki;
+    MDBX_val key = mdbxgo_int_val(&ki, k, kn), val = {0};
+    r.err = mdbx_get(txn, dbi, &key, &val);
+    r.vbase = val.iov_base;
+    r.vlen = val.iov_len;
+    return r;
+}
+
+int mdbxgo_put_int(MDBX_txn *txn, MDBX_dbi dbi, uint64_t k, size_t kn, char *vdata, size_t vn, MDBX_put_flags_t flags) {
+    mdbxgo_int ki;
+    MDBX_val key = mdbxgo_int_val(&ki, k, kn), val;
+    MDBXGO_SET_VAL(&val, vn, vdata);
+    return mdbx_put(txn, dbi, &key, &val, flags);
+}
+
+int mdbxgo_put_ints(MDBX_txn *txn, MDBX_dbi dbi, uint64_t k, uint64_t v, size_t n, MDBX_put_flags_t flags) {
+    mdbxgo_int ki, vi;
+    MDBX_val key = mdbxgo_int_val(&ki, k, n), val = mdbxgo_int_val(&vi, v, n);
+    return mdbx_put(txn, dbi, &key, &val, flags);
+}
+
+mdbxgo_val_result mdbxgo_cursor_get_int(MDBX_cursor *cur, uint64_t k, size_t kn, MDBX_cursor_op op) {
+    mdbxgo_val_result r = {0};
+    mdbxgo_int ki;
+    MDBX_val key = mdbxgo_int_val(&ki, k, kn), val = {0};
+    r.err = mdbx_cursor_get(cur, &key, &val, op);
+    MDBXGO_SET_VAL_RESULT(r, key, val);
+    if (r.kbase == (char *)&ki) {
+        r.kbase = NULL;
+    }
+    return r;
+}
+
+mdbxgo_val_result mdbxgo_cursor_get_ints(MDBX_cursor *cur, uint64_t k, uint64_t v, size_t n, MDBX_cursor_op op) {
+    mdbxgo_val_result r = {0};
+    mdbxgo_int ki, vi;
+    MDBX_val key = mdbxgo_int_val(&ki, k, n), val = mdbxgo_int_val(&vi, v, n);
+    r.err = mdbx_cursor_get(cur, &key, &val, op);
+    MDBXGO_SET_VAL_RESULT(r, key, val);
+    if (r.kbase == (char *)&ki) {
+        r.kbase = NULL;
+    }
+    if (r.vbase == (char *)&vi) {
+        r.vbase = NULL;
+    }
+    return r;
+}
+
+int mdbxgo_cursor_put_int(MDBX_cursor *cur, uint64_t k, size_t kn, char *vdata, size_t vn, MDBX_put_flags_t flags) {
+    mdbxgo_int ki;
+    MDBX_val key = mdbxgo_int_val(&ki, k, kn), val;
+    MDBXGO_SET_VAL(&val, vn, vdata);
+    return mdbx_cursor_put(cur, &key, &val, flags);
+}
+
+int mdbxgo_cursor_put_ints(MDBX_cursor *cur, uint64_t k, uint64_t v, size_t n, MDBX_put_flags_t flags) {
+    mdbxgo_int ki, vi;
+    MDBX_val key = mdbxgo_int_val(&ki, k, n), val = mdbxgo_int_val(&vi, v, n);
+    return mdbx_cursor_put(cur, &key, &val, flags);
+}
+
+static int mdbxgo_preserve_proxy(void *ctx, MDBX_val *target, const void *src, size_t bytes) {
+    (void)target;
+    return mdbxgoPreserveBridge((size_t)(uintptr_t)ctx, (char *)src, bytes);
+}
+
+mdbxgo_val_result mdbxgo_replace(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, char *vdata, size_t vn, bool del,
+                                 char *odata, size_t on, MDBX_put_flags_t flags, size_t ctx) {
+    mdbxgo_val_result r = {0};
+    MDBX_val key, val, old;
+    MDBXGO_SET_VAL(&key, kn, kdata);
+    MDBXGO_SET_VAL(&val, vn, vdata);
+    MDBXGO_SET_VAL(&old, on, odata);
+    if (ctx) {
+        r.err = mdbx_replace_ex(txn, dbi, &key, del ? NULL : &val, &old, flags, &mdbxgo_preserve_proxy, (void *)(uintptr_t)ctx);
+    } else {
+        r.err = mdbx_replace(txn, dbi, &key, del ? NULL : &val, &old, flags);
+    }
+    r.vbase = old.iov_base;
+    r.vlen = old.iov_len;
+    return r;
+}
+
+int mdbxgo_compare_and_swap(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, char *edata, size_t en, bool insert,
+                            char *vdata, size_t vn, bool del) {
+    MDBX_val key, expected, val, present;
+    unsigned flags, state;
+    int rc = mdbx_dbi_flags_ex(txn, dbi, &flags, &state);
+    if (rc != MDBX_SUCCESS) {
+        return rc;
+    }
+    MDBXGO_SET_VAL(&key, kn, kdata);
+    MDBXGO_SET_VAL(&expected, en, edata);
+    MDBXGO_SET_VAL(&val, vn, vdata);
+    if (insert) {
+        return mdbx_put(txn, dbi, &key, &val, (flags & MDBX_DUPSORT) ? MDBX_NODUPDATA : MDBX_NOOVERWRITE);
+    }
+    if (flags & MDBX_DUPSORT) {
+        /* expected selects the item among the values of the key */
+        return mdbx_replace_ex(txn, dbi, &key, del ? NULL : &val, &expected, MDBX_CURRENT | MDBX_NOOVERWRITE, NULL, NULL);
+    }
+    /* the cursor compares and replaces the value it found, in one descent */
+    MDBX_cursor *cur = mdbx_cursor_create(NULL);
+    if (!cur) {
+        return MDBX_ENOMEM;
+    }
+    rc = mdbx_cursor_bind(txn, cur, dbi);
+    if (rc == MDBX_SUCCESS) {
+        rc = mdbx_cursor_get(cur, &key, &present, MDBX_SET_KEY);
+    }
+    if (rc == MDBX_SUCCESS) {
+        if (present.iov_len != en || (en && memcmp(present.iov_base, edata, en) != 0)) {
+            rc = MDBX_RESULT_TRUE;
+        } else if (del) {
+            rc = mdbx_cursor_del(cur, 0);
+        } else {
+            rc = mdbx_cursor_put(cur, &key, &val, MDBX_CURRENT);
+        }
+    }
+    mdbx_cursor_close(cur);
+    return rc;
+}
+
+mdbxgo_cache_result mdbxgo_cache_get(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, MDBX_cache_entry_t *entry) {
+    mdbxgo_cache_result r = {0};
+    MDBX_val key, val = {0};
+    MDBXGO_SET_VAL(&key, kn, kdata);
+    MDBX_cache_result_t res = mdbx_cache_get(txn, dbi, &key, &val, entry);
+    r.err = res.errcode;
+    r.status = res.status;
+    r.vbase = val.iov_base;
+    r.vlen = val.iov_len;
+    return r;
+}
+
+void mdbxgo_cache_init(MDBX_cache_entry_t *entry) {
+    mdbx_cache_init(entry);
+}
+
+mdbxgo_size_result mdbxgo_cursor_get_batch(MDBX_cursor *cur, char *pairs, size_t limit, MDBX_cursor_op op) {
+    mdbxgo_size_result r = {0};
+    r.err = mdbx_cursor_get_batch(cur, &r.val, (MDBX_val *)pairs, limit, op);
+    return r;
+}
+
+static int mdbxgo_table_enum_proxy(void *ctx, const MDBX_txn *txn, const MDBX_val *name, MDBX_db_flags_t flags,
+                                   const struct MDBX_stat *stat, MDBX_dbi dbi) {
+    (void)txn;
+    return mdbxgoTableEnumBridge((size_t)ctx, name->iov_base, name->iov_len, flags, (MDBX_stat *)stat, dbi);
+}
+
+int mdbxgo_enumerate_tables(MDBX_txn *txn, size_t ctx) {
+    return mdbx_enumerate_tables(txn, &mdbxgo_table_enum_proxy, (void *)ctx);
+}
+
 /* Compare two items lexically */
 // static int __hot cmp_lexical(const MDBX_val *a, const MDBX_val *b) {
 //   if (a->iov_len == b->iov_len)
@@ -138,6 +341,49 @@ mdbxgo_size_result mdbxgo_cursor_count(MDBX_cursor *cur) {
     return r;
 }
 
+mdbxgo_int_result mdbxgo_cursor_compare(const MDBX_cursor *left, const MDBX_cursor *right, bool ignore_multival) {
+    mdbxgo_int_result r = {0};
+    /* mdbx_cursor_compare has no error results and its order of cursors that
+     * are not comparable is undefined, so these are checked beforehand. */
+    if (!left || !right) {
+        r.err = MDBX_EINVAL;
+        return r;
+    }
+    if (!mdbxgo_cursor_on(left, MDBXGO_ON_PAIR) || !mdbxgo_cursor_on(right, MDBXGO_ON_PAIR)) {
+        r.err = MDBX_ENODATA;
+        return r;
+    }
+    if (mdbx_cursor_txn(left) != mdbx_cursor_txn(right) ||
+        mdbx_cursor_dbi(left) != mdbx_cursor_dbi(right)) {
+        r.err = MDBX_EINVAL;
+        return r;
+    }
+    int diff = mdbx_cursor_compare(left, right, ignore_multival);
+    r.val = (diff > 0) - (diff < 0);
+    return r;
+}
+
+bool mdbxgo_cursor_on(const MDBX_cursor *cur, int which) {
+    /* the count is zero unless the cursor is on a pair */
+    size_t count = 0;
+    if (mdbx_cursor_count(cur, &count) != MDBX_SUCCESS || count == 0) {
+        return false;
+    }
+    switch (which) {
+    case MDBXGO_ON_PAIR:
+        return true;
+    case MDBXGO_ON_FIRST:
+        return mdbx_cursor_on_first(cur) == MDBX_RESULT_TRUE;
+    case MDBXGO_ON_LAST:
+        return mdbx_cursor_on_last(cur) == MDBX_RESULT_TRUE;
+    case MDBXGO_ON_FIRST_DUP:
+        return mdbx_cursor_on_first_dup(cur) == MDBX_RESULT_TRUE;
+    case MDBXGO_ON_LAST_DUP:
+        return mdbx_cursor_on_last_dup(cur) == MDBX_RESULT_TRUE;
+    }
+    return false;
+}
+
 mdbxgo_u64_result mdbxgo_cursor_bunch_delete(MDBX_cursor *cur, MDBX_bunch_action_t mode) {
     mdbxgo_u64_result r = {0};
     r.err = mdbx_cursor_bunch_delete(cur, mode, &r.val);
@@ -246,6 +492,125 @@ mdbxgo_uint_result mdbxgo_dbi_open(MDBX_txn *txn, const char *name, MDBX_db_flag
     return r;
 }
 
+typedef struct {
+    int kind, cmp;
+    size_t offset;
+    MDBX_val a, b;
+    size_t handle;
+    MDBX_val key, val;
+} mdbxgo_predicate;
+
+static int mdbxgo_lexcmp(const MDBX_val *x, const MDBX_val *y) {
+    size_t n = x->iov_len < y->iov_len ? x->iov_len : y->iov_len;
+    int c = n ? memcmp(x->iov_base, y->iov_base, n) : 0;
+    if (c != 0) {
+        return c;
+    }
+    return (x->iov_len > y->iov_len) - (x->iov_len < y->iov_len);
+}
+
+static bool mdbxgo_field_match(const mdbxgo_predicate *p, const MDBX_val *x) {
+    if (x->iov_len < p->offset || x->iov_len - p->offset < p->a.iov_len) {
+        return false;
+    }
+    int c = p->a.iov_len ? memcmp((const char *)x->iov_base + p->offset, p->a.iov_base, p->a.iov_len) : 0;
+    switch (p->cmp) {
+    case MDBXGO_FIELD_EQ:
+        return c == 0;
+    case MDBXGO_FIELD_NE:
+        return c != 0;
+    case MDBXGO_FIELD_LT:
+        return c < 0;
+    case MDBXGO_FIELD_LE:
+        return c <= 0;
+    case MDBXGO_FIELD_GT:
+        return c > 0;
+    case MDBXGO_FIELD_GE:
+        return c >= 0;
+    }
+    return false;
+}
+
+static int mdbxgo_predicate_proxy(void *context, MDBX_val *key, MDBX_val *value, void *arg) {
+    (void)arg;
+    mdbxgo_predicate *p = context;
+    int rc = MDBX_RESULT_FALSE;
+    switch (p->kind) {
+    case MDBXGO_PRED_KEY_PREFIX:
+        if (key->iov_len >= p->a.iov_len && (!p->a.iov_len || memcmp(key->iov_base, p->a.iov_base, p->a.iov_len) == 0)) {
+            rc = MDBX_RESULT_TRUE;
+        }
+        break;
+    case MDBXGO_PRED_KEY_RANGE:
+        /* an empty upper bound is none */
+        if (mdbxgo_lexcmp(key, &p->a) >= 0 && (!p->b.iov_len || mdbxgo_lexcmp(key, &p->b) < 0)) {
+            rc = MDBX_RESULT_TRUE;
+        }
+        break;
+    case MDBXGO_PRED_VALUE_EQUAL:
+        if (mdbxgo_lexcmp(value, &p->a) == 0) {
+            rc = MDBX_RESULT_TRUE;
+        }
+        break;
+    case MDBXGO_PRED_KEY_FIELD:
+        if (mdbxgo_field_match(p, key)) {
+            rc = MDBX_RESULT_TRUE;
+        }
+        break;
+    case MDBXGO_PRED_VALUE_FIELD:
+        if (mdbxgo_field_match(p, value)) {
+            rc = MDBX_RESULT_TRUE;
+        }
+        break;
+    case MDBXGO_PRED_GO:
+        rc = mdbxgoScanPredicateBridge(p->handle, key->iov_base, key->iov_len, value->iov_base, value->iov_len);
+        break;
+    default:
+        rc = MDBX_EINVAL;
+    }
+    if (rc == MDBX_RESULT_TRUE) {
+        p->key = *key;
+        p->val = *value;
+    }
+    return rc;
+}
+
+mdbxgo_val_result mdbxgo_cursor_scan(MDBX_cursor *cur, int kind, int cmp, size_t offset, char *adata, size_t an,
+                                     char *bdata, size_t bn, size_t handle, bool from, MDBX_cursor_op start_op,
+                                     char *kdata, size_t kn, char *vdata, size_t vn, MDBX_cursor_op turn_op) {
+    mdbxgo_val_result r = {0};
+    mdbxgo_predicate p = {.kind = kind, .cmp = cmp, .offset = offset, .handle = handle};
+    MDBXGO_SET_VAL(&p.a, an, adata);
+    MDBXGO_SET_VAL(&p.b, bn, bdata);
+    if (from) {
+        MDBX_val key, val;
+        MDBXGO_SET_VAL(&key, kn, kdata);
+        MDBXGO_SET_VAL(&val, vn, vdata);
+        r.err = mdbx_cursor_scan_from(cur, &mdbxgo_predicate_proxy, &p, start_op, &key, &val, turn_op, NULL);
+    } else {
+        r.err = mdbx_cursor_scan(cur, &mdbxgo_predicate_proxy, &p, start_op, turn_op, NULL);
+    }
+    MDBXGO_SET_VAL_RESULT(r, p.key, p.val);
+    return r;
+}
+
+mdbxgo_canary_result mdbxgo_env_canary(MDBX_env *env) {
+    mdbxgo_canary_result r = {0};
+    MDBX_txn *txn = NULL;
+    r.err = mdbx_txn_begin(env, NULL, MDBX_TXN_RDONLY, &txn);
+    if (r.err == MDBX_SUCCESS) {
+        r.err = mdbx_canary_get(txn, &r.canary);
+        mdbx_txn_abort(txn);
+    }
+    return r;
+}
+
+int mdbxgo_dbi_rename(MDBX_txn *txn, MDBX_dbi dbi, char *name, size_t n) {
+    MDBX_val val;
+    MDBXGO_SET_VAL(&val, n, name);
+    return mdbx_dbi_rename2(txn, dbi, &val);
+}
+
 mdbxgo_uint_result mdbxgo_dbi_open_ex(MDBX_txn *txn, const char *name, MDBX_db_flags_t flags, MDBX_cmp_func *cmp, MDBX_cmp_func *dcmp) {
     mdbxgo_uint_result r = {0};
     MDBX_dbi dbi = 0;
@@ -302,6 +667,14 @@ mdbxgo_int_result mdbxgo_env_get_fd(MDBX_env *env) {
     return r;
 }
 #endif
+
+int mdbxgo_env_copy2fd(MDBX_env *env, uintptr_t fd, MDBX_copy_flags_t flags) {
+    return mdbx_env_copy2fd(env, (mdbx_filehandle_t)fd, flags);
+}
+
+int mdbxgo_txn_copy2fd(MDBX_txn *txn, uintptr_t fd, MDBX_copy_flags_t flags) {
+    return mdbx_txn_copy2fd(txn, (mdbx_filehandle_t)fd, flags);
+}
 int mdbxgo_cmp(MDBX_txn *txn, MDBX_dbi dbi, char *adata, size_t an, char *bdata, size_t bn) {
     MDBX_val a;
     MDBXGO_SET_VAL(&a, an, adata);
@@ -317,3 +690,302 @@ int mdbxgo_dcmp(MDBX_txn *txn, MDBX_dbi dbi, char *adata, size_t an, char *bdata
     MDBXGO_SET_VAL(&b, bn, bdata);
     return mdbx_dcmp(txn, dbi, &a, &b);
 }
+
+int mdbxgo_defrag_notify_proxy(void *ctx, const MDBX_defrag_result_t *progress) {
+    return mdbxgoDefragNotifyBridge((size_t)ctx, (MDBX_defrag_result_t *)progress);
+}
+
+mdbxgo_defrag_result mdbxgo_env_defrag(MDBX_env *env, size_t atleast, size_t time_atleast_dot16,
+                                       size_t enough, size_t time_limit_dot16,
+                                       intptr_t backlash, size_t preferred_batch, size_t ctx) {
+    mdbxgo_defrag_result r = {0};
+    r.err = mdbx_env_defrag(env, atleast, time_atleast_dot16, enough, time_limit_dot16, backlash,
+                            (intptr_t)preferred_batch, &mdbxgo_defrag_notify_proxy, (void *)ctx, &r.res);
+    return r;
+}
+
+/* The buffer is guarded by the global debug lock of libmdbx, which is held
+ * while messages are formatted and logged. */
+static char mdbxgo_log_buffer[4096];
+
+static void mdbxgo_log_proxy(MDBX_log_level_t level, const char *function, int line, const char *msg,
+                             unsigned length) {
+    mdbxgoLogBridge(level, (char *)function, line, (char *)msg, length);
+}
+
+int mdbxgo_setup_logger(MDBX_log_level_t level, MDBX_debug_flags_t flags, bool enable) {
+    if (enable) {
+        return mdbx_setup_debug_nofmt(level, flags, &mdbxgo_log_proxy, mdbxgo_log_buffer, sizeof(mdbxgo_log_buffer));
+    }
+    return mdbx_setup_debug(level, flags, NULL);
+}
+
+static int mdbxgo_hsr_proxy(const MDBX_env *env, const MDBX_txn *txn, mdbx_pid_t pid, mdbx_tid_t tid,
+                            uint64_t laggard, unsigned gap, size_t space, int retry) {
+    (void)txn;
+    return mdbxgoHSRBridge((size_t)mdbx_env_get_userctx(env), pid, mdbxgo_tid_to_u64(tid), laggard, gap, space, retry);
+}
+
+int mdbxgo_env_set_hsr(MDBX_env *env, bool enable) {
+    return mdbx_env_set_hsr(env, enable ? &mdbxgo_hsr_proxy : NULL);
+}
+
+int mdbxgo_env_set_userctx(MDBX_env *env, size_t ctx) {
+    return mdbx_env_set_userctx(env, (void *)ctx);
+}
+
+int mdbxgo_txn_set_userctx(MDBX_txn *txn, size_t ctx) {
+    return mdbx_txn_set_userctx(txn, (void *)ctx);
+}
+
+int mdbxgo_cursor_set_userctx(MDBX_cursor *cur, size_t ctx) {
+    return mdbx_cursor_set_userctx(cur, (void *)ctx);
+}
+
+mdbxgo_chk *mdbxgo_chk_new(size_t handle) {
+    mdbxgo_chk *chk = calloc(1, sizeof(mdbxgo_chk));
+    if (chk) {
+        chk->handle = handle;
+    }
+    return chk;
+}
+
+void mdbxgo_chk_break(mdbxgo_chk *chk) {
+    __atomic_store_n(&chk->brk, 1, __ATOMIC_RELAXED);
+}
+
+static MDBX_chk_stage_t mdbxgo_chk_stage(const MDBX_chk_context_t *ctx) {
+    return ctx->scope ? ctx->scope->stage : MDBX_chk_none;
+}
+
+/* mdbxgo_chk_table returns the table being processed by the current scope as
+ * tracked by mdbxgo_chk_scope_push, or NULL. */
+static const MDBX_chk_table_t *mdbxgo_chk_table(const MDBX_chk_context_t *ctx) {
+    return ctx->scope ? (const MDBX_chk_table_t *)ctx->scope->usr_o.ptr : NULL;
+}
+
+/* mdbxgo_chk_table_name returns the name of table, spelling the pseudo-names
+ * of the core tables like mdbx_chk does. */
+static char *mdbxgo_chk_table_name(const MDBX_chk_table_t *table, size_t *len) {
+    const char *name;
+    if (!table) {
+        *len = 0;
+        return NULL;
+    } else if (table->name.iov_base == MDBX_CHK_MAIN) {
+        name = "@MAIN";
+    } else if (table->name.iov_base == MDBX_CHK_GC) {
+        name = "@GC";
+    } else if (table->name.iov_base == MDBX_CHK_META) {
+        name = "@META";
+    } else {
+        *len = table->name.iov_len;
+        return table->name.iov_base;
+    }
+    *len = strlen(name);
+    return (char *)name;
+}
+
+static bool mdbxgo_chk_check_break(MDBX_chk_context_t *ctx) {
+    return __atomic_load_n(&((mdbxgo_chk *)ctx)->brk, __ATOMIC_RELAXED) != 0;
+}
+
+static int mdbxgo_chk_scope_push(MDBX_chk_context_t *ctx, MDBX_chk_scope_t *outer, MDBX_chk_scope_t *inner,
+                                 const char *fmt, va_list args) {
+    (void)ctx;
+    (void)fmt;
+    (void)args;
+    inner->usr_o.ptr = inner->object ? (void *)inner->object : (outer ? outer->usr_o.ptr : NULL);
+    return MDBX_SUCCESS;
+}
+
+static void mdbxgo_chk_issue(MDBX_chk_context_t *ctx, const char *object, uint64_t entry_number, const char *caption,
+                             const char *extra_fmt, va_list extra_args) {
+    char extra[256] = "";
+    if (extra_fmt) {
+        vsnprintf(extra, sizeof(extra), extra_fmt, extra_args);
+    }
+    size_t tlen;
+    char *tname = mdbxgo_chk_table_name(mdbxgo_chk_table(ctx), &tlen);
+    mdbxgoChkIssueBridge(((mdbxgo_chk *)ctx)->handle, mdbxgo_chk_stage(ctx), tname, tlen, (char *)object,
+                         entry_number, (char *)caption, extra);
+}
+
+static int mdbxgo_chk_table_conclude(MDBX_chk_context_t *ctx, const MDBX_chk_table_t *table, MDBX_cursor *cursor,
+                                     int err) {
+    (void)cursor;
+    int kind = MDBXGO_CHK_TABLE;
+    if (table->name.iov_base == MDBX_CHK_MAIN) {
+        kind = MDBXGO_CHK_MAINDB;
+    } else if (table->name.iov_base == MDBX_CHK_GC) {
+        kind = MDBXGO_CHK_GCDB;
+    }
+    size_t nlen;
+    char *name = mdbxgo_chk_table_name(table, &nlen);
+    mdbxgoChkTableBridge(((mdbxgo_chk *)ctx)->handle, kind, name, nlen, (MDBX_chk_table_t *)table);
+    return err;
+}
+
+static MDBX_chk_line_t *mdbxgo_chk_print_begin(MDBX_chk_context_t *ctx, MDBX_chk_severity_t severity) {
+    mdbxgo_chk *chk = (mdbxgo_chk *)ctx;
+    const MDBX_chk_severity_t cutoff = ctx->scope ? ctx->scope->verbosity : MDBX_chk_warning;
+    if ((severity >> MDBX_chk_severity_prio_shift) > (cutoff >> MDBX_chk_severity_prio_shift)) {
+        return NULL;
+    }
+    chk->line.ctx = NULL;
+    chk->line.severity = (uint8_t)severity;
+    chk->line.scope_depth = ctx->scope_nesting;
+    chk->line.empty = true;
+    chk->line.begin = chk->line.out = chk->buf;
+    chk->line.end = chk->buf + sizeof(chk->buf);
+    return &chk->line;
+}
+
+static void mdbxgo_chk_print_done(MDBX_chk_line_t *line) {
+    if (line->out > line->begin) {
+        size_t tlen;
+        char *tname = mdbxgo_chk_table_name(mdbxgo_chk_table(line->ctx), &tlen);
+        mdbxgoChkLineBridge(((mdbxgo_chk *)line->ctx)->handle, mdbxgo_chk_stage(line->ctx), line->severity, tname,
+                            tlen, line->begin, (size_t)(line->out - line->begin));
+    }
+    line->ctx = NULL;
+}
+
+int mdbxgo_env_chk(MDBX_env *env, mdbxgo_chk *chk, MDBX_chk_flags_t flags, MDBX_chk_severity_t verbosity) {
+    static const MDBX_chk_callbacks_t cb = {
+        .check_break = mdbxgo_chk_check_break,
+        .scope_push = mdbxgo_chk_scope_push,
+        .issue = mdbxgo_chk_issue,
+        .table_conclude = mdbxgo_chk_table_conclude,
+        .print_begin = mdbxgo_chk_print_begin,
+        .print_done = mdbxgo_chk_print_done,
+    };
+    return mdbx_env_chk(env, &cb, &chk->ctx, flags, verbosity, 0);
+}
+
+static int mdbxgo_cmp_uint64_be(const MDBX_val *a, const MDBX_val *b) {
+    const uint8_t *pa = a->iov_base, *pb = b->iov_base;
+    size_t la = a->iov_len, lb = b->iov_len;
+    if (la == 8 && lb == 8) {
+        int r = memcmp(pa, pb, 8);
+        return (r > 0) - (r < 0);
+    }
+    /* compare the significant bytes, then the encodings of equal values. */
+    size_t za = 0, zb = 0;
+    while (za < la && pa[za] == 0)
+        za++;
+    while (zb < lb && pb[zb] == 0)
+        zb++;
+    if (la - za != lb - zb)
+        return (la - za < lb - zb) ? -1 : 1;
+    int r = memcmp(pa + za, pb + zb, la - za);
+    if (r != 0)
+        return (r > 0) - (r < 0);
+    return (la > lb) - (la < lb);
+}
+
+static int mdbxgo_cmp_bytes(const uint8_t *pa, size_t la, const uint8_t *pb, size_t lb) {
+    int r = memcmp(pa, pb, la < lb ? la : lb);
+    if (r != 0)
+        return (r > 0) - (r < 0);
+    return (la > lb) - (la < lb);
+}
+
+static int mdbxgo_cmp_descending(const MDBX_val *a, const MDBX_val *b) {
+    return mdbxgo_cmp_bytes(b->iov_base, b->iov_len, a->iov_base, a->iov_len);
+}
+
+/* mdbxgo_uvarint decodes the length prefix of encoding/binary.PutUvarint,
+ * returning the number of bytes read, or 0 if the prefix is malformed. */
+static size_t mdbxgo_uvarint(const uint8_t *p, size_t n, uint64_t *v) {
+    uint64_t x = 0;
+    for (size_t i = 0; i < n && i < 10; i++) {
+        if (p[i] < 0x80) {
+            if (i == 9 && p[i] > 1)
+                return 0;
+            *v = x | (uint64_t)p[i] << (7 * i);
+            return i + 1;
+        }
+        x |= (uint64_t)(p[i] & 0x7f) << (7 * i);
+    }
+    return 0;
+}
+
+/* mdbxgo_split_field splits a key into its length-prefixed field and the
+ * suffix.  A key with a malformed prefix is all field. */
+static void mdbxgo_split_field(const MDBX_val *key, const uint8_t **f, size_t *fn, const uint8_t **s, size_t *sn) {
+    const uint8_t *p = key->iov_base;
+    uint64_t n;
+    size_t h = mdbxgo_uvarint(p, key->iov_len, &n);
+    if (h == 0 || n > key->iov_len - h) {
+        *f = p, *fn = key->iov_len;
+        *s = p, *sn = 0;
+        return;
+    }
+    *f = p + h, *fn = (size_t)n;
+    *s = p + h + n, *sn = key->iov_len - h - (size_t)n;
+}
+
+static int mdbxgo_cmp_length_prefixed(const MDBX_val *a, const MDBX_val *b) {
+    const uint8_t *fa, *sa, *fb, *sb;
+    size_t fna, sna, fnb, snb;
+    mdbxgo_split_field(a, &fa, &fna, &sa, &sna);
+    mdbxgo_split_field(b, &fb, &fnb, &sb, &snb);
+    int r = mdbxgo_cmp_bytes(fa, fna, fb, fnb);
+    if (r == 0)
+        r = mdbxgo_cmp_bytes(sa, sna, sb, snb);
+    if (r == 0)
+        r = mdbxgo_cmp_bytes(a->iov_base, a->iov_len, b->iov_base, b->iov_len);
+    return r;
+}
+
+#define MDBXGO_CMP_SLOT_LIST(X) \
+    X(0) X(1) X(2) X(3) X(4) X(5) X(6) X(7) X(8) X(9) X(10) X(11) X(12) X(13) X(14) X(15) \
+    X(16) X(17) X(18) X(19) X(20) X(21) X(22) X(23) X(24) X(25) X(26) X(27) X(28) X(29) X(30) X(31) \
+    X(32) X(33) X(34) X(35) X(36) X(37) X(38) X(39) X(40) X(41) X(42) X(43) X(44) X(45) X(46) X(47) \
+    X(48) X(49) X(50) X(51) X(52) X(53) X(54) X(55) X(56) X(57) X(58) X(59) X(60) X(61) X(62) X(63) \
+    X(64) X(65) X(66) X(67) X(68) X(69) X(70) X(71) X(72) X(73) X(74) X(75) X(76) X(77) X(78) X(79) \
+    X(80) X(81) X(82) X(83) X(84) X(85) X(86) X(87) X(88) X(89) X(90) X(91) X(92) X(93) X(94) X(95) \
+    X(96) X(97) X(98) X(99) X(100) X(101) X(102) X(103) X(104) X(105) X(106) X(107) X(108) X(109) X(110) X(111) \
+    X(112) X(113) X(114) X(115) X(116) X(117) X(118) X(119) X(120) X(121) X(122) X(123) X(124) X(125) X(126) X(127)
+
+#define MDBXGO_CMP_TRAMPOLINES(i) \
+    static int mdbxgo_cmp_key_##i(const MDBX_val *a, const MDBX_val *b) { \
+        return mdbxgoCompareBridge(2 * (i), a->iov_base, a->iov_len, b->iov_base, b->iov_len); \
+    } \
+    static int mdbxgo_cmp_data_##i(const MDBX_val *a, const MDBX_val *b) { \
+        return mdbxgoCompareBridge(2 * (i) + 1, a->iov_base, a->iov_len, b->iov_base, b->iov_len); \
+    }
+MDBXGO_CMP_SLOT_LIST(MDBXGO_CMP_TRAMPOLINES)
+
+#define MDBXGO_CMP_KEY(i) mdbxgo_cmp_key_##i,
+#define MDBXGO_CMP_DATA(i) mdbxgo_cmp_data_##i,
+static const MDBX_cmp_func mdbxgo_cmp_slots[2][MDBXGO_CMP_SLOTS] = {
+    {MDBXGO_CMP_SLOT_LIST(MDBXGO_CMP_KEY)},
+    {MDBXGO_CMP_SLOT_LIST(MDBXGO_CMP_DATA)},
+};
+
+static MDBX_cmp_func mdbxgo_cmp_func(int cmp, int slot, int data) {
+    switch (cmp) {
+    case MDBXGO_CMP_GO:
+        return (slot >= 0 && slot < MDBXGO_CMP_SLOTS) ? mdbxgo_cmp_slots[data][slot] : NULL;
+    case MDBXGO_CMP_UINT64_BE:
+        return mdbxgo_cmp_uint64_be;
+    case MDBXGO_CMP_DESCENDING:
+        return mdbxgo_cmp_descending;
+    case MDBXGO_CMP_LENGTH_PREFIXED:
+        return mdbxgo_cmp_length_prefixed;
+    default:
+        return NULL;
+    }
+}
+
+mdbxgo_uint_result mdbxgo_dbi_open_cmp(MDBX_txn *txn, const char *name, MDBX_db_flags_t flags, int kcmp, int dcmp,
+                                       int slot) {
+    mdbxgo_uint_result r = {0};
+    MDBX_dbi dbi = 0;
+    r.err = mdbx_dbi_open_ex(txn, name, flags, &dbi, mdbxgo_cmp_func(kcmp, slot, 0), mdbxgo_cmp_func(dcmp, slot, 1));
+    if (r.err == MDBX_SUCCESS) {
+        r.val = dbi;
+    }
+    return r;
+}
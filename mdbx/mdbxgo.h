@@ -5,8 +5,18 @@
 #ifndef _MDBXGO_H_
 #define _MDBXGO_H_
 
+#include <limits.h>
 #include "../libmdbx/mdbx.h"
 
+/* mdbxgo_forks counts the forks the process descends from, as seen by the
+ * pthread_atfork handler mdbxgo_install_fork_guard installs.  It is
+ * incremented in the child only, so an Env opened at another count was
+ * inherited over a fork. */
+extern unsigned mdbxgo_forks;
+#ifndef _WIN32
+int mdbxgo_install_fork_guard(void);
+#endif
+
 /* Proxy functions for lmdb get/put operations. The functions are defined to
  * take char* values instead of void* to keep cgo from cheking their data for
  * nested pointers and causing a couple of allocations per argument.
@@ -19,6 +29,8 @@
  * */
 int mdbxgo_del(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, char *vdata, size_t vn);
 int mdbxgo_get(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, MDBX_val *val);
+typedef struct { int err; char *vbase; size_t vlen; size_t count; } mdbxgo_get_ex_result;
+mdbxgo_get_ex_result mdbxgo_get_ex(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn);
 int mdbxgo_put1(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, MDBX_val *val, MDBX_put_flags_t flags);
 int mdbxgo_put2(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, char *vdata, size_t vn, MDBX_put_flags_t flags);
 int mdbxgo_cursor_put1(MDBX_cursor *cur, char *kdata, size_t kn, MDBX_val *val, MDBX_put_flags_t flags);
@@ -29,6 +41,9 @@ int mdbxgo_cursor_putmulti(MDBX_cursor *cur, char *kdata, size_t kn, char *vdata
  * mdbxgoMDBReaderListBridge external Go func.
  * */
 int mdbxgo_reader_list(MDBX_env *env, size_t ctx);
+/* mdbxgo_enumerate_tables is a proxy for mdbx_enumerate_tables that relays
+ * the tables over the mdbxgoTableEnumBridge external Go func. */
+int mdbxgo_enumerate_tables(MDBX_txn *txn, size_t ctx);
 uint64_t mdbxgo_tid_to_u64(mdbx_tid_t tid);
 uint64_t mdbxgo_tid_txn_parked(void);
 uint64_t mdbxgo_tid_txn_ousted(void);
@@ -47,6 +62,7 @@ typedef struct { int err; ptrdiff_t val; } mdbxgo_ptrdiff_result;
 typedef struct { int err; intptr_t pageSize, totalPages, availPages; } mdbxgo_sysraminfo_result;
 
 mdbxgo_size_result       mdbxgo_cursor_count(MDBX_cursor *cur);
+mdbxgo_int_result        mdbxgo_cursor_compare(const MDBX_cursor *left, const MDBX_cursor *right, bool ignore_multival);
 mdbxgo_u64_result        mdbxgo_cursor_bunch_delete(MDBX_cursor *cur, MDBX_bunch_action_t mode);
 mdbxgo_u64_result        mdbxgo_cursor_delete_range(MDBX_cursor *begin, MDBX_cursor *end, bool end_including);
 mdbxgo_ptrdiff_result    mdbxgo_estimate_distance(const MDBX_cursor *first, const MDBX_cursor *last);
@@ -63,6 +79,12 @@ mdbxgo_uint_result       mdbxgo_dbi_flags(MDBX_txn *txn, MDBX_dbi dbi);
 mdbxgo_sysraminfo_result mdbxgo_get_sysraminfo(void);
 mdbxgo_uint_result       mdbxgo_dbi_open(MDBX_txn *txn, const char *name, MDBX_db_flags_t flags);
 mdbxgo_uint_result       mdbxgo_dbi_open_ex(MDBX_txn *txn, const char *name, MDBX_db_flags_t flags, MDBX_cmp_func *cmp, MDBX_cmp_func *dcmp);
+int                      mdbxgo_dbi_rename(MDBX_txn *txn, MDBX_dbi dbi, char *name, size_t n);
+
+/* mdbxgo_env_canary reads the canary of the last committed transaction with
+ * a short-lived read transaction. */
+typedef struct { int err; MDBX_canary canary; } mdbxgo_canary_result;
+mdbxgo_canary_result     mdbxgo_env_canary(MDBX_env *env);
 
 typedef struct { int err; MDBX_commit_latency lat; } mdbxgo_commit_result;
 mdbxgo_commit_result     mdbxgo_txn_commit_ex(MDBX_txn *txn);
@@ -70,10 +92,80 @@ mdbxgo_commit_result     mdbxgo_txn_checkpoint(MDBX_txn *txn, MDBX_txn_flags_t w
 mdbxgo_commit_result     mdbxgo_txn_commit_embark_read(MDBX_txn **ptxn);
 
 typedef struct { int err; char *kbase; size_t klen; char *vbase; size_t vlen; } mdbxgo_val_result;
+/* mdbxgo_cache_get is a proxy for mdbx_cache_get, entry is in Go memory. */
+typedef struct { int err; int status; char *vbase; size_t vlen; } mdbxgo_cache_result;
+mdbxgo_cache_result      mdbxgo_cache_get(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, MDBX_cache_entry_t *entry);
+void                     mdbxgo_cache_init(MDBX_cache_entry_t *entry);
 mdbxgo_val_result        mdbxgo_cursor_get_empty(MDBX_cursor *cur, MDBX_cursor_op op);
 mdbxgo_val_result        mdbxgo_cursor_get_val(MDBX_cursor *cur, char *kdata, size_t kn, char *vdata, size_t vn, MDBX_cursor_op op);
 mdbxgo_val_result        mdbxgo_cursor_put_reserve(MDBX_cursor *cur, char *kdata, size_t kn, size_t vn, MDBX_put_flags_t flags);
 
+/* Proxies for IntegerKey and IntegerDup tables taking the integers by value,
+ * so that no Go memory is passed.  kn and n are the sizes of the integers,
+ * 4 or 8, stored in native byte order.  The *_ints variants take an integer
+ * value of the same size as the key.  The cursor ones return a NULL key or
+ * value where mdbx_cursor_get left the one passed in. */
+mdbxgo_val_result        mdbxgo_get_int(MDBX_txn *txn, MDBX_dbi dbi, uint64_t k, size_t kn);
+int                      mdbxgo_put_int(MDBX_txn *txn, MDBX_dbi dbi, uint64_t k, size_t kn, char *vdata, size_t vn, MDBX_put_flags_t flags);
+int                      mdbxgo_put_ints(MDBX_txn *txn, MDBX_dbi dbi, uint64_t k, uint64_t v, size_t n, MDBX_put_flags_t flags);
+mdbxgo_val_result        mdbxgo_cursor_get_int(MDBX_cursor *cur, uint64_t k, size_t kn, MDBX_cursor_op op);
+mdbxgo_val_result        mdbxgo_cursor_get_ints(MDBX_cursor *cur, uint64_t k, uint64_t v, size_t n, MDBX_cursor_op op);
+int                      mdbxgo_cursor_put_int(MDBX_cursor *cur, uint64_t k, size_t kn, char *vdata, size_t vn, MDBX_put_flags_t flags);
+int                      mdbxgo_cursor_put_ints(MDBX_cursor *cur, uint64_t k, uint64_t v, size_t n, MDBX_put_flags_t flags);
+
+/* mdbxgo_get_equal_or_great is a proxy for mdbx_get_equal_or_great that
+ * returns the pair found.  No value is passed to it, so in DupSort tables
+ * it finds the first value of the key. */
+mdbxgo_val_result        mdbxgo_get_equal_or_great(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn);
+
+/* Predicates of mdbxgo_cursor_scan, evaluated in C except for
+ * MDBXGO_PRED_GO, which relays the pairs over the mdbxgoScanPredicateBridge
+ * external Go func. */
+#define MDBXGO_PRED_KEY_PREFIX  1
+#define MDBXGO_PRED_KEY_RANGE   2
+#define MDBXGO_PRED_VALUE_EQUAL 3
+#define MDBXGO_PRED_KEY_FIELD   4
+#define MDBXGO_PRED_VALUE_FIELD 5
+#define MDBXGO_PRED_GO          6
+
+/* MDBXGO_SCAN_ABORT is returned by mdbxgoScanPredicateBridge to stop a scan
+ * on a panic.  It is no error code of libmdbx. */
+#define MDBXGO_SCAN_ABORT INT_MIN
+
+/* Comparisons of a field with the operand of a MDBXGO_PRED_*_FIELD. */
+#define MDBXGO_FIELD_EQ 0
+#define MDBXGO_FIELD_NE 1
+#define MDBXGO_FIELD_LT 2
+#define MDBXGO_FIELD_LE 3
+#define MDBXGO_FIELD_GT 4
+#define MDBXGO_FIELD_GE 5
+
+/* mdbxgo_cursor_scan is a proxy for mdbx_cursor_scan, or for
+ * mdbx_cursor_scan_from if from is set, that probes the pairs with the
+ * predicate of the given kind, operands a and b and field offset.  The
+ * returned key and value are those of the matching pair. */
+mdbxgo_val_result        mdbxgo_cursor_scan(MDBX_cursor *cur, int kind, int cmp, size_t offset, char *adata, size_t an,
+                                            char *bdata, size_t bn, size_t handle, bool from, MDBX_cursor_op start_op,
+                                            char *kdata, size_t kn, char *vdata, size_t vn, MDBX_cursor_op turn_op);
+
+/* Positions tested by mdbxgo_cursor_on. */
+#define MDBXGO_ON_PAIR      0
+#define MDBXGO_ON_FIRST     1
+#define MDBXGO_ON_LAST      2
+#define MDBXGO_ON_FIRST_DUP 3
+#define MDBXGO_ON_LAST_DUP  4
+
+/* mdbxgo_cursor_on reports whether the cursor is on a pair, and on the given
+ * boundary.  Unlike mdbx_cursor_on_first and the like it reports false for a
+ * cursor that is not on a pair, whether unpositioned or past the end, which
+ * mdbx_cursor_eof cannot tell from a cursor on the last pair. */
+bool                     mdbxgo_cursor_on(const MDBX_cursor *cur, int which);
+
+/* mdbxgo_cursor_get_batch fills pairs, an array of limit MDBX_val passed as
+ * char* for the reason given above, with alternating keys and values.  val is
+ * the number of MDBX_val filled. */
+mdbxgo_size_result       mdbxgo_cursor_get_batch(MDBX_cursor *cur, char *pairs, size_t limit, MDBX_cursor_op op);
+
 typedef struct {
     int err;
     uint64_t pages_allocated;
@@ -91,4 +183,90 @@ mdbxgo_gc_info_result    mdbxgo_gc_info(MDBX_txn *txn);
 mdbxgo_int_result        mdbxgo_env_get_fd(MDBX_env *env);
 #endif
 
+/* mdbxgo_env_defrag is a proxy for mdbx_env_defrag that relays progress
+ * notifications over the mdbxgoDefragNotifyBridge external Go func. */
+typedef struct { int err; MDBX_defrag_result_t res; } mdbxgo_defrag_result;
+mdbxgo_defrag_result     mdbxgo_env_defrag(MDBX_env *env, size_t atleast, size_t time_atleast_dot16,
+                                           size_t enough, size_t time_limit_dot16,
+                                           intptr_t backlash, size_t preferred_batch, size_t ctx);
+
+/* mdbxgo_setup_logger sets the global log level and debug flags of libmdbx
+ * and, if enabled, a logger that relays preformatted messages over the
+ * mdbxgoLogBridge external Go func.  Otherwise the default logger of libmdbx
+ * is restored. */
+int mdbxgo_setup_logger(MDBX_log_level_t level, MDBX_debug_flags_t flags, bool enable);
+
+/* mdbxgo_env_set_hsr installs (or removes) a static Handle-Slow-Readers
+ * callback that relays calls over the mdbxgoHSRBridge external Go func,
+ * using the user context of env as the handle. */
+int mdbxgo_env_set_hsr(MDBX_env *env, bool enable);
+
+/* Proxies for the user contexts of envs, txns and cursors, which hold the
+ * integer handles of their Go objects, see userctx. */
+int mdbxgo_env_set_userctx(MDBX_env *env, size_t ctx);
+int mdbxgo_txn_set_userctx(MDBX_txn *txn, size_t ctx);
+int mdbxgo_cursor_set_userctx(MDBX_cursor *cur, size_t ctx);
+
+/* mdbxgo_replace is a proxy for mdbx_replace_ex that deletes the key if del
+ * is set.  With a zero ctx the previous value is copied into the odata
+ * buffer by the default preserver of mdbx_replace, otherwise it is relayed
+ * over the mdbxgoPreserveBridge external Go func.  The returned value is the
+ * old_data of mdbx_replace_ex. */
+mdbxgo_val_result        mdbxgo_replace(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, char *vdata, size_t vn, bool del,
+                                        char *odata, size_t on, MDBX_put_flags_t flags, size_t ctx);
+/* mdbxgo_compare_and_swap replaces (or deletes, if del is set) the value of a
+ * key if it equals edata, or inserts the value if insert is set and the key
+ * is absent.  In DupSort tables edata selects the value to replace.  It
+ * returns MDBX_RESULT_TRUE if the value differs.  The key is looked up once,
+ * by a cursor which then replaces the value in place, as the preserver of
+ * mdbx_replace_ex is not called for values on clean pages, so it cannot
+ * veto the replacement. */
+int mdbxgo_compare_and_swap(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, char *edata, size_t en, bool insert,
+                            char *vdata, size_t vn, bool del);
+
+/* The copy2fd proxies take the descriptor as an integer so the Go side passes
+ * os.File.Fd() unchanged on both POSIX (int fd) and Windows (HANDLE). */
+int mdbxgo_env_copy2fd(MDBX_env *env, uintptr_t fd, MDBX_copy_flags_t flags);
+int mdbxgo_txn_copy2fd(MDBX_txn *txn, uintptr_t fd, MDBX_copy_flags_t flags);
+
+/* mdbxgo_chk bundles the context of mdbx_env_chk with the state of the proxy
+ * callbacks, which relay issues, report lines and table summaries over the
+ * mdbxgoChk*Bridge external Go funcs.  It is allocated in C memory so that
+ * mdbxgo_chk_break may be called from another goroutine while the check
+ * runs, without a Go callback for every visited record. */
+typedef struct {
+    MDBX_chk_context_t ctx; /* must be the first member */
+    size_t handle;
+    int brk;
+    MDBX_chk_line_t line;
+    char buf[1024];
+} mdbxgo_chk;
+
+/* Kinds of tables passed to mdbxgoChkTableBridge. */
+#define MDBXGO_CHK_TABLE 0
+#define MDBXGO_CHK_MAINDB 1
+#define MDBXGO_CHK_GCDB 2
+
+mdbxgo_chk              *mdbxgo_chk_new(size_t handle);
+void                     mdbxgo_chk_break(mdbxgo_chk *chk);
+int                      mdbxgo_env_chk(MDBX_env *env, mdbxgo_chk *chk, MDBX_chk_flags_t flags,
+                                        MDBX_chk_severity_t verbosity);
+
+/* Comparators of mdbxgo_dbi_open_cmp.  MDBXGO_CMP_NONE keeps the comparator
+ * the table has or its flags imply, MDBXGO_CMP_GO relays to the Go comparator
+ * registered in the slot over the mdbxgoCompareBridge external Go func, and
+ * the others are implemented in C. */
+#define MDBXGO_CMP_NONE 0
+#define MDBXGO_CMP_GO 1
+#define MDBXGO_CMP_UINT64_BE 2
+#define MDBXGO_CMP_DESCENDING 3
+#define MDBXGO_CMP_LENGTH_PREFIXED 4
+
+/* The number of slots for Go comparators.  libmdbx passes comparators no
+ * context, so each slot has its own pair of trampolines for keys and data. */
+#define MDBXGO_CMP_SLOTS 128
+
+mdbxgo_uint_result       mdbxgo_dbi_open_cmp(MDBX_txn *txn, const char *name, MDBX_db_flags_t flags, int kcmp,
+                                             int dcmp, int slot);
+
 #endif
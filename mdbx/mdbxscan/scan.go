@@ -0,0 +1,231 @@
+/*
+Package mdbxscan provides a Scanner type, which iterates over the pairs of a
+table with a cursor, like bufio.Scanner iterates over the lines of a file.  It
+is the lmdbscan package of lmdb-go, ported to mdbx.
+
+	s := mdbxscan.New(txn, dbi)
+	defer s.Close()
+	for s.Scan() {
+		fmt.Println(string(s.Key()), string(s.Val()))
+	}
+	return s.Err()
+
+The methods Set and SetNext move the cursor before the scan, for example to
+scan a range in reverse, and Filter restricts the pairs scanned, for example
+to the keys with a prefix.
+
+	s.Filter(mdbxscan.Prefix(prefix))
+	s.Set(prefix, nil, mdbx.SetRange)
+	for s.Scan() {
+		...
+	}
+
+The cursor of a Scanner is taken from the pool of package mdbx, and returned
+to it by Close, see mdbx.CursorFromPool.
+*/
+package mdbxscan
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/erigontech/mdbx-go/mdbx"
+)
+
+var errClosed = errors.New("mdbxscan: scanner is closed")
+
+// Scanner is a low level construct for scanning over the pairs of a table in
+// a Txn.  A Scanner is not safe for concurrent use, and must be closed before
+// its Txn ends.
+type Scanner struct {
+	cur      *mdbx.Cursor
+	op       uint
+	set      bool // the pair of Set is yet to be scanned
+	done     bool // Set found no pair, or a While filter stopped the scan
+	key, val []byte
+	err      error
+	filters  []Filter
+}
+
+// New returns a Scanner of the pairs of dbi in txn, from the first.  An error
+// opening the cursor is reported by Err, and the Scanner scans no pairs.
+func New(txn *mdbx.Txn, dbi mdbx.DBI) *Scanner {
+	s := &Scanner{op: mdbx.Next}
+	c := mdbx.CursorFromPool()
+	if err := c.Bind(txn, dbi); err != nil {
+		mdbx.CursorToPool(c)
+		s.err = err
+		return s
+	}
+	s.cur = c
+	return s
+}
+
+// Cursor returns the cursor of s, or nil after Close.  Moving the cursor
+// moves the scan along, and deleting the current pair with it is the way to
+// delete pairs while scanning.
+func (s *Scanner) Cursor() *mdbx.Cursor {
+	return s.cur
+}
+
+// Filter restricts the pairs scanned by s to those passing each of filters,
+// in addition to the filters given before.  The filters are checked in order.
+func (s *Scanner) Filter(filters ...Filter) {
+	s.filters = append(s.filters, filters...)
+}
+
+// Set moves the cursor of s to the pair found by opset with k and v, such as
+// mdbx.SetRange, which the next Scan returns unless a filter skips it.  Set
+// reports whether a pair was found.
+func (s *Scanner) Set(k, v []byte, opset uint) bool {
+	if !s.open() {
+		return false
+	}
+	s.set, s.done = false, false
+	s.key, s.val, s.err = s.cur.Get(k, v, opset)
+	if s.err != nil {
+		if mdbx.IsNotFound(s.err) {
+			s.err = nil
+		}
+		s.done = true
+		return false
+	}
+	s.set = true
+	return true
+}
+
+// SetNext is Set, which also makes opnext the op of the following calls to
+// Scan, such as mdbx.Prev to scan in reverse.
+func (s *Scanner) SetNext(k, v []byte, opset, opnext uint) bool {
+	s.op = opnext
+	return s.Set(k, v, opset)
+}
+
+// Scan moves s to the next pair passing its filters, and reports whether
+// there was one.  Scan returns false once the pairs are exhausted, a While
+// filter stopped the scan, or an error occurred, see Err.
+func (s *Scanner) Scan() bool {
+	return s.scan(s.op)
+}
+
+// ScanKey is Scan moving to the first value of the next key of a DupSort
+// table, skipping the other values of the current key, with mdbx.NextNoDup.
+func (s *Scanner) ScanKey() bool {
+	return s.scan(mdbx.NextNoDup)
+}
+
+// ScanDup is Scan moving to the next value of the current key of a DupSort
+// table, with mdbx.NextDup.  ScanDup returns false after the last value.
+func (s *Scanner) ScanDup() bool {
+	return s.scan(mdbx.NextDup)
+}
+
+func (s *Scanner) scan(op uint) bool {
+	if !s.open() || s.err != nil || s.done && !s.set {
+		return false
+	}
+	for {
+		if s.set {
+			s.set = false
+		} else {
+			s.key, s.val, s.err = s.cur.Get(nil, nil, op)
+		}
+		if s.err != nil {
+			if mdbx.IsNotFound(s.err) {
+				s.err = nil
+			}
+			s.key, s.val = nil, nil
+			return false
+		}
+		switch s.check() {
+		case pass:
+			return true
+		case stop:
+			s.done = true
+			s.key, s.val = nil, nil
+			return false
+		}
+	}
+}
+
+// Key returns the key of the pair of the last Scan, a view into the
+// database like those of mdbx.Txn.Get.
+func (s *Scanner) Key() []byte {
+	return s.key
+}
+
+// Val returns the value of the pair of the last Scan, a view into the
+// database like those of mdbx.Txn.Get.
+func (s *Scanner) Val() []byte {
+	return s.val
+}
+
+// Err returns the error which stopped the scan, or nil if the pairs were
+// exhausted.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// Close returns the cursor of s to the pool.  Calling Close more than once
+// does nothing, and the methods of a closed Scanner fail.
+func (s *Scanner) Close() {
+	if s.cur != nil {
+		mdbx.CursorToPool(s.cur)
+		s.cur = nil
+		s.key, s.val = nil, nil
+	}
+}
+
+func (s *Scanner) open() bool {
+	if s.cur == nil {
+		if s.err == nil {
+			s.err = errClosed
+		}
+		return false
+	}
+	return true
+}
+
+// Filter restricts the pairs of a Scanner, see Scanner.Filter.
+type Filter struct {
+	keep func(k, v []byte) bool
+	stop bool // stop rather than skip a pair which is not kept
+}
+
+type outcome int
+
+const (
+	pass outcome = iota
+	skip
+	stop
+)
+
+func (s *Scanner) check() outcome {
+	for _, f := range s.filters {
+		if !f.keep(s.key, s.val) {
+			if f.stop {
+				return stop
+			}
+			return skip
+		}
+	}
+	return pass
+}
+
+// While passes the pairs for which fn returns true, and stops the scan at the
+// first pair for which it returns false.
+func While(fn func(k, v []byte) bool) Filter {
+	return Filter{keep: fn, stop: true}
+}
+
+// Skip skips the pairs for which fn returns true.
+func Skip(fn func(k, v []byte) bool) Filter {
+	return Filter{keep: func(k, v []byte) bool { return !fn(k, v) }}
+}
+
+// Prefix is While the keys start with prefix.  The scan is to be started at
+// the prefix, with Set(prefix, nil, mdbx.SetRange), as the keys before it are
+// not skipped.
+func Prefix(prefix []byte) Filter {
+	return While(func(k, _ []byte) bool { return bytes.HasPrefix(k, prefix) })
+}
@@ -0,0 +1,195 @@
+package mdbxscan
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/erigontech/mdbx-go/mdbx"
+)
+
+func setup(t *testing.T, flags uint, pairs ...string) (*mdbx.Env, mdbx.DBI) {
+	t.Helper()
+	env, err := mdbx.NewEnv(mdbx.Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { env.Close() })
+	if err := env.SetOption(mdbx.OptMaxDB, 16); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.Open(t.TempDir(), 0, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var dbi mdbx.DBI
+	err = env.Update(func(txn *mdbx.Txn) (err error) {
+		if dbi, err = txn.OpenDBISimple("scan", mdbx.Create|flags); err != nil {
+			return err
+		}
+		for _, p := range pairs {
+			k, v, _ := strings.Cut(p, "=")
+			if err := txn.Put(dbi, []byte(k), []byte(v), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env, dbi
+}
+
+// scanAll returns the pairs scanned by s with scan as "key=val".
+func scanAll(t *testing.T, s *Scanner, scan func() bool) []string {
+	t.Helper()
+	var got []string
+	for scan() {
+		got = append(got, string(s.Key())+"="+string(s.Val()))
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("scan: %v", err)
+	}
+	return got
+}
+
+func TestScanner(t *testing.T) {
+	pairs := []string{"a=1", "ab=2", "abc=3", "b=4", "ba=5", "c=6"}
+	env, dbi := setup(t, 0, pairs...)
+	err := env.View(func(txn *mdbx.Txn) error {
+		s := New(txn, dbi)
+		defer s.Close()
+		if got := scanAll(t, s, s.Scan); !slices.Equal(got, pairs) {
+			t.Errorf("unexpected scan %q", got)
+		}
+		// a Set pair is scanned next.
+		if !s.Set([]byte("abb"), nil, mdbx.SetRange) || string(s.Key()) != "abc" {
+			t.Errorf("unexpected Set result %q", s.Key())
+		}
+		if got := scanAll(t, s, s.Scan); !slices.Equal(got, pairs[2:]) {
+			t.Errorf("unexpected scan after Set %q", got)
+		}
+		if !s.SetNext([]byte("b"), nil, mdbx.SetKey, mdbx.Prev) {
+			t.Error("SetNext found no pair")
+		}
+		if got := scanAll(t, s, s.Scan); !slices.Equal(got, []string{"b=4", "abc=3", "ab=2", "a=1"}) {
+			t.Errorf("unexpected reverse scan %q", got)
+		}
+		if s.Set([]byte("bb"), nil, mdbx.SetKey) || s.Scan() || s.Err() != nil {
+			t.Errorf("unexpected scan after a missing key: %v", s.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScanner_Filter(t *testing.T) {
+	env, dbi := setup(t, 0, "a=1", "ab=2", "abc=3", "abd=4", "ac=5", "b=6")
+	err := env.View(func(txn *mdbx.Txn) error {
+		s := New(txn, dbi)
+		defer s.Close()
+		s.Filter(Prefix([]byte("ab")))
+		s.Set([]byte("ab"), nil, mdbx.SetRange)
+		if got := scanAll(t, s, s.Scan); !slices.Equal(got, []string{"ab=2", "abc=3", "abd=4"}) {
+			t.Errorf("unexpected prefix scan %q", got)
+		}
+		// a stopped scan stays stopped until Set.
+		if s.Scan() {
+			t.Errorf("unexpected scan after a stop %q", s.Key())
+		}
+
+		// the Set pair is filtered too, and filters compose.
+		s.Filter(Skip(func(_, v []byte) bool { return string(v) == "3" }))
+		s.Set([]byte("abc"), nil, mdbx.SetKey)
+		if got := scanAll(t, s, s.Scan); !slices.Equal(got, []string{"abd=4"}) {
+			t.Errorf("unexpected filtered scan %q", got)
+		}
+
+		s2 := New(txn, dbi)
+		defer s2.Close()
+		s2.Filter(
+			Skip(func(k, _ []byte) bool { return len(k) > 2 }),
+			While(func(_, v []byte) bool { return v[0] < '6' }),
+		)
+		if got := scanAll(t, s2, s2.Scan); !slices.Equal(got, []string{"a=1", "ab=2", "ac=5"}) {
+			t.Errorf("unexpected filtered scan %q", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScanner_dupSort(t *testing.T) {
+	env, dbi := setup(t, mdbx.DupSort, "a=1", "a=2", "a=3", "b=1", "c=1", "c=2")
+	err := env.View(func(txn *mdbx.Txn) error {
+		s := New(txn, dbi)
+		defer s.Close()
+		if got := scanAll(t, s, s.ScanKey); !slices.Equal(got, []string{"a=1", "b=1", "c=1"}) {
+			t.Errorf("unexpected scan of keys %q", got)
+		}
+		s.Set([]byte("a"), nil, mdbx.SetKey)
+		if got := scanAll(t, s, s.ScanDup); !slices.Equal(got, []string{"a=1", "a=2", "a=3"}) {
+			t.Errorf("unexpected scan of values %q", got)
+		}
+		// the scan goes on from the last value.
+		if got := scanAll(t, s, s.Scan); !slices.Equal(got, []string{"b=1", "c=1", "c=2"}) {
+			t.Errorf("unexpected scan after the values %q", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScanner_errors(t *testing.T) {
+	env, dbi := setup(t, 0, "a=1")
+	err := env.View(func(txn *mdbx.Txn) error {
+		s := New(txn, dbi+100)
+		if s.Scan() || !mdbx.IsErrno(s.Err(), mdbx.BadDBI) || s.Cursor() != nil {
+			t.Errorf("unexpected scan of a bad dbi: %v", s.Err())
+		}
+		s.Close()
+
+		s = New(txn, dbi)
+		if !s.Scan() {
+			t.Fatal(s.Err())
+		}
+		s.Close()
+		s.Close()
+		if s.Scan() || s.Set(nil, nil, mdbx.First) || !errors.Is(s.Err(), errClosed) || s.Key() != nil {
+			t.Errorf("unexpected scan of a closed scanner: %v", s.Err())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScanner_allocs(t *testing.T) {
+	env, dbi := setup(t, 0, "a=1", "b=2", "c=3")
+	err := env.View(func(txn *mdbx.Txn) error {
+		// warm the cursor pool.
+		New(txn, dbi).Close()
+		allocs := testing.AllocsPerRun(100, func() {
+			s := New(txn, dbi)
+			for s.Scan() {
+			}
+			s.Close()
+		})
+		// the Scanner itself.
+		if allocs > 1 {
+			t.Errorf("%v allocations", allocs)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
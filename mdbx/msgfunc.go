@@ -5,11 +5,67 @@ package mdbx
 */
 import "C"
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"sync"
-	"sync/atomic"
+	"unsafe"
 )
 
+// handles is a registry of callback state, or other Go values, that C code
+// refers to.  It keeps Go pointers out of C memory by storing the values in a
+// Go map and passing only an integer handle through libmdbx.
+//
+// An external map is used because struct pointers passed to C functions must
+// not contain pointers in their struct fields.  See the following language
+// proposal which discusses the restrictions on passing pointers to C.
+//
+//	https://github.com/golang/proposal/blob/master/design/12416-cgo-pointers.md
+type handles[H ~uintptr, T any] struct {
+	mu sync.RWMutex
+	n  uint64
+	m  map[H]*T
+}
+
+// register stores v under a new handle, which is never zero nor reused.
+func (hs *handles[H, T]) register(v *T) H {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if hs.m == nil {
+		hs.m = map[H]*T{}
+	}
+	hs.n++
+	h := H(hs.n)
+	hs.m[h] = v
+	return h
+}
+
+// store stores v under h, which is not made by register, replacing the value
+// stored before.
+func (hs *handles[H, T]) store(h H, v *T) {
+	hs.mu.Lock()
+	if hs.m == nil {
+		hs.m = map[H]*T{}
+	}
+	hs.m[h] = v
+	hs.mu.Unlock()
+}
+
+func (hs *handles[H, T]) deregister(h H) {
+	hs.mu.Lock()
+	delete(hs.m, h)
+	hs.mu.Unlock()
+}
+
+// get returns the value stored under h, or nil.
+func (hs *handles[H, T]) get(h H) *T {
+	hs.mu.RLock()
+	v := hs.m[h]
+	hs.mu.RUnlock()
+	return v
+}
+
 // mdbxgoMDBReaderListBridge provides a static C function for handling
 // MDBX_reader_list_func callbacks.  It performs type conversion and dynamic
 // dispatch to a callback provided to Env.ReaderList.  Any error returned by the
@@ -18,7 +74,7 @@ import (
 //
 //export mdbxgoMDBReaderListBridge
 func mdbxgoMDBReaderListBridge(_ctx C.size_t, num C.int, slot C.int, pid C.mdbx_pid_t, thread C.uint64_t, txnid C.uint64_t, lag C.uint64_t, bytesUsed C.size_t, bytesRetained C.size_t) (rc C.int) {
-	ctx := readerctx(_ctx).get()
+	ctx := readerctxs.get(readerctx(_ctx))
 	defer func() {
 		if r := recover(); r != nil {
 			ctx.err = fmt.Errorf("mdbx: panic in ReaderList callback: %v", r)
@@ -49,49 +105,444 @@ func mdbxgoMDBReaderListBridge(_ctx C.size_t, num C.int, slot C.int, pid C.mdbx_
 type readerfunc func(ReaderInfo) error
 
 // readerctx is the type used for context pointers passed to mdbx_reader_list.
-// It keeps Go pointers out of C memory by storing callback state in a Go map
-// and passing only an integer handle through libmdbx.
-//
-// An external map is used because struct pointers passed to C functions must
-// not contain pointers in their struct fields.  See the following language
-// proposal which discusses the restrictions on passing pointers to C.
-//
-//	https://github.com/golang/proposal/blob/master/design/12416-cgo-pointers.md
 type readerctx uintptr
 type _readerctx struct {
 	fn  readerfunc
 	err error
 }
 
-var readerctxn uint32
-var readerctxm = map[readerctx]*_readerctx{}
-var readerctxmlock sync.RWMutex
+var readerctxs handles[readerctx, _readerctx]
 
 func newReaderFunc(fn readerfunc) (ctx readerctx, done func()) {
-	ctx = readerctx(atomic.AddUint32(&readerctxn, 1))
-	ctx.register(fn)
-	return ctx, ctx.deregister
+	ctx = readerctxs.register(&_readerctx{fn: fn})
+	return ctx, func() { readerctxs.deregister(ctx) }
+}
+
+// mdbxgoPreserveBridge provides a static C function for handling
+// MDBX_preserve_func callbacks.  It passes the value about to be replaced to
+// the callback provided to Txn.ReplaceFunc.  A panic is cached and aborts the
+// replacement.
+//
+//export mdbxgoPreserveBridge
+func mdbxgoPreserveBridge(_ctx C.size_t, src *C.char, n C.size_t) (rc C.int) {
+	ctx := preservectxs.get(preservectx(_ctx))
+	defer func() {
+		if r := recover(); r != nil {
+			ctx.err = fmt.Errorf("mdbx: panic in ReplaceFunc callback: %v", r)
+			rc = C.MDBX_RESULT_TRUE
+		}
+	}()
+
+	ctx.called = true
+	ctx.fn(castToBytesRaw(unsafe.Pointer(src), n))
+	return success
+}
+
+type preservefunc func(old []byte)
+
+// preservectx is the handle of a Txn.ReplaceFunc callback.  See handles.
+type preservectx uintptr
+type _preservectx struct {
+	fn     preservefunc
+	called bool
+	err    error
+}
+
+var preservectxs handles[preservectx, _preservectx]
+
+func newPreserveFunc(fn preservefunc) (ctx preservectx, done func()) {
+	ctx = preservectxs.register(&_preservectx{fn: fn})
+	return ctx, func() { preservectxs.deregister(ctx) }
+}
+
+// mdbxgoTableEnumBridge provides a static C function for handling
+// MDBX_table_enum_func callbacks.  It passes each table to the callback
+// provided to Txn.ForEachTable.  An error returned by the callback, or a
+// panic, is cached and ends the enumeration.
+//
+//export mdbxgoTableEnumBridge
+func mdbxgoTableEnumBridge(_ctx C.size_t, name unsafe.Pointer, namelen C.size_t, flags C.MDBX_db_flags_t, stat *C.MDBX_stat, dbi C.MDBX_dbi) (rc C.int) {
+	ctx := tablectxs.get(tablectx(_ctx))
+	defer func() {
+		if r := recover(); r != nil {
+			ctx.err = fmt.Errorf("mdbx: panic in ForEachTable callback: %v", r)
+			rc = C.MDBX_RESULT_TRUE
+		}
+	}()
+
+	info := TableInfo{
+		Name:  string(castToBytesRaw(name, namelen)),
+		Flags: uint(flags),
+		Stat:  castStat(stat),
+		DBI:   DBI(dbi),
+	}
+	if err := ctx.fn(info); err != nil {
+		ctx.err = err
+		return C.MDBX_RESULT_TRUE
+	}
+	return success
+}
+
+type tablefunc func(TableInfo) error
+
+// tablectx is the handle of a Txn.ForEachTable callback.  See handles.
+type tablectx uintptr
+type _tablectx struct {
+	fn  tablefunc
+	err error
+}
+
+var tablectxs handles[tablectx, _tablectx]
+
+func newTableFunc(fn tablefunc) (ctx tablectx, done func()) {
+	ctx = tablectxs.register(&_tablectx{fn: fn})
+	return ctx, func() { tablectxs.deregister(ctx) }
+}
+
+// mdbxgoScanPredicateBridge provides a static C function for probing pairs
+// with the Go predicate of Cursor.Scan and Cursor.ScanFrom.  A panic is
+// cached and stops the scan.
+//
+//export mdbxgoScanPredicateBridge
+func mdbxgoScanPredicateBridge(_ctx C.size_t, k unsafe.Pointer, kn C.size_t, v unsafe.Pointer, vn C.size_t) (rc C.int) {
+	ctx := scanctxs.get(scanctx(_ctx))
+	defer func() {
+		if r := recover(); r != nil {
+			ctx.err = fmt.Errorf("mdbx: panic in Scan predicate: %v", r)
+			rc = C.MDBXGO_SCAN_ABORT
+		}
+	}()
+
+	if ctx.fn(castToBytesRaw(k, kn), castToBytesRaw(v, vn)) {
+		return C.MDBX_RESULT_TRUE
+	}
+	return C.MDBX_RESULT_FALSE
+}
+
+type scanfunc func(key, val []byte) bool
+
+// scanctx is the handle of a Go predicate of Cursor.Scan.  See handles.
+type scanctx uintptr
+type _scanctx struct {
+	fn  scanfunc
+	err error
+}
+
+var scanctxs handles[scanctx, _scanctx]
+
+func newScanFunc(fn scanfunc) (ctx scanctx, done func()) {
+	ctx = scanctxs.register(&_scanctx{fn: fn})
+	return ctx, func() { scanctxs.deregister(ctx) }
+}
+
+// mdbxgoDefragNotifyBridge provides a static C function for handling
+// MDBX_defrag_notify_func callbacks.  It converts the progress record and
+// dispatches it to the callback provided to Env.Defrag.  Cancellation of the
+// Defrag context, a callback error or a panic aborts the defragmentation and
+// is cached for Env.Defrag to return; ErrDefragDiscontinue discontinues it
+// gracefully.
+//
+//export mdbxgoDefragNotifyBridge
+func mdbxgoDefragNotifyBridge(_ctx C.size_t, progress *C.MDBX_defrag_result_t) (rc C.int) {
+	ctx := defragctxs.get(defragctx(_ctx))
+	defer func() {
+		if r := recover(); r != nil {
+			ctx.err = fmt.Errorf("mdbx: panic in Defrag progress callback: %v", r)
+			rc = defragAbort
+		}
+	}()
+
+	if err := ctx.ctx.Err(); err != nil {
+		ctx.err = err
+		return defragAbort
+	}
+	if ctx.fn == nil {
+		return defragContinue
+	}
+	err := ctx.fn(castDefragResult(progress))
+	switch {
+	case err == nil:
+		return defragContinue
+	case errors.Is(err, ErrDefragDiscontinue):
+		return defragDiscontinue
+	default:
+		ctx.err = err
+		return defragAbort
+	}
+}
+
+// Return values of MDBX_defrag_notify_func.
+const (
+	defragContinue    C.int = 0
+	defragDiscontinue C.int = 1
+	defragAbort       C.int = -1
+)
+
+type defragfunc func(DefragResult) error
+
+// defragctx is the type used for context pointers passed to mdbx_env_defrag.
+type defragctx uintptr
+type _defragctx struct {
+	ctx context.Context
+	fn  defragfunc
+	err error
+}
+
+var defragctxs handles[defragctx, _defragctx]
+
+func newDefragFunc(ctx context.Context, fn defragfunc) (_ctx defragctx, done func()) {
+	_ctx = defragctxs.register(&_defragctx{ctx: ctx, fn: fn})
+	return _ctx, func() { defragctxs.deregister(_ctx) }
+}
+
+// mdbxgoLogBridge provides a static C function for handling
+// MDBX_debug_func_nofmt callbacks.  It passes the message to the handler
+// installed by SetLogger.  A panic in the handler drops the message.
+//
+//export mdbxgoLogBridge
+func mdbxgoLogBridge(level C.int, function *C.char, line C.int, msg *C.char, length C.uint) {
+	l := currentLogger.Load()
+	if l == nil {
+		return
+	}
+	defer func() {
+		_ = recover()
+	}()
+	l.log(LogLvl(level), C.GoString(function), int(line), C.GoStringN(msg, C.int(length)))
+}
+
+// mdbxgoHSRBridge provides a static C function for handling MDBX_hsr_func
+// callbacks.  It dispatches to the callback installed by Env.SetHSR on the
+// Env whose handle is the user context of the C env, and carries out
+// HSRKill.  A panic in the callback, or a failure to kill the reader, makes
+// libmdbx give up on the reader.
+//
+//export mdbxgoHSRBridge
+func mdbxgoHSRBridge(_ctx C.size_t, pid C.mdbx_pid_t, tid C.uint64_t, laggard C.uint64_t, gap C.uint, space C.size_t, retry C.int) (rc C.int) {
+	var fn *hsrfunc
+	if env, _ := userObject(userctx(_ctx)).(*Env); env != nil {
+		fn = env.hsr.Load()
+	}
+	if fn == nil {
+		return C.int(HSRGiveUp)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			rc = C.int(HSRGiveUp)
+		}
+	}()
+
+	r := SlowReader{
+		PID:     int(pid),
+		TID:     uint64(tid),
+		Laggard: uint64(laggard),
+		Gap:     uint(gap),
+		Space:   uint64(space),
+		Retry:   int(retry),
+	}
+	decision := (*fn)(r)
+	switch decision {
+	case HSRGiveUp, HSRRetry, HSROust:
+	case HSRKill:
+		if err := killReader(r.PID); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return hsrFailed
+		}
+	default:
+		return hsrFailed
+	}
+	return C.int(decision)
+}
+
+// hsrFailed is returned from MDBX_hsr_func on an error, when the reader was
+// not dealt with.
+const hsrFailed C.int = -2
+
+type hsrfunc func(SlowReader) HSRDecision
+
+// mdbxgoChkIssueBridge provides a static C function for handling the issue
+// callback of MDBX_chk_callbacks_t.  It records the issue in the report of
+// Env.Check.
+//
+//export mdbxgoChkIssueBridge
+func mdbxgoChkIssueBridge(_ctx C.size_t, stage C.int, table *C.char, tlen C.size_t, object *C.char, entry C.uint64_t, caption *C.char, extra *C.char) {
+	ctx := checkctxs.get(checkctx(_ctx))
+	issue := CheckIssue{
+		Severity: CheckError,
+		Stage:    CheckStage(stage),
+		Table:    C.GoStringN(table, C.int(tlen)),
+		Entry:    -1,
+		Message:  C.GoString(extra),
+	}
+	if object != nil {
+		issue.Object = C.GoString(object)
+		if entry != C.UINT64_MAX {
+			issue.Entry = int64(entry)
+		}
+	}
+	if caption != nil {
+		if issue.Message != "" {
+			issue.Message = C.GoString(caption) + " (" + issue.Message + ")"
+		} else {
+			issue.Message = C.GoString(caption)
+		}
+	}
+	ctx.report.addIssue(issue)
+}
+
+// mdbxgoChkLineBridge provides a static C function for handling the lines of
+// the human-readable report of mdbx_env_chk.  Lines of warning severity and
+// above are recorded as issues, and all are passed to CheckOptions.Log.  A
+// panic in Log is cached and interrupts the check.
+//
+//export mdbxgoChkLineBridge
+func mdbxgoChkLineBridge(_ctx C.size_t, stage C.int, severity C.int, table *C.char, tlen C.size_t, text *C.char, n C.size_t) {
+	ctx := checkctxs.get(checkctx(_ctx))
+	defer func() {
+		if r := recover(); r != nil {
+			if ctx.err == nil {
+				ctx.err = fmt.Errorf("mdbx: panic in Check log callback: %v", r)
+			}
+			C.mdbxgo_chk_break(ctx.chk)
+		}
+	}()
+
+	sev := CheckSeverity(severity)
+	line := C.GoStringN(text, C.int(n))
+	if sev.prio() <= CheckWarning.prio() {
+		ctx.report.addIssue(CheckIssue{
+			Severity: sev,
+			Stage:    CheckStage(stage),
+			Table:    C.GoStringN(table, C.int(tlen)),
+			Entry:    -1,
+			Message:  line,
+		})
+	}
+	if ctx.log != nil && ctx.err == nil {
+		ctx.log(sev, CheckStage(stage), line)
+	}
+}
+
+// mdbxgoChkTableBridge provides a static C function for handling the
+// table_conclude callback of MDBX_chk_callbacks_t.  It records the summary of
+// the table in the report of Env.Check.
+//
+//export mdbxgoChkTableBridge
+func mdbxgoChkTableBridge(_ctx C.size_t, kind C.int, name *C.char, n C.size_t, table *C.MDBX_chk_table_t) {
+	ctx := checkctxs.get(checkctx(_ctx))
+	tbl := castCheckTable(C.GoStringN(name, C.int(n)), table)
+	switch kind {
+	case C.MDBXGO_CHK_MAINDB:
+		ctx.report.Main = tbl
+	case C.MDBXGO_CHK_GCDB:
+		ctx.report.GC.Table = tbl
+	default:
+		ctx.report.Tables = append(ctx.report.Tables, tbl)
+	}
+}
+
+type checklogfunc func(CheckSeverity, CheckStage, string)
+
+// checkctx is the type used for context pointers passed to mdbx_env_chk.
+type checkctx uintptr
+type _checkctx struct {
+	chk    *C.mdbxgo_chk
+	report *CheckReport
+	log    checklogfunc
+	err    error
+}
+
+var checkctxs handles[checkctx, _checkctx]
+
+func newCheckFunc(report *CheckReport, log checklogfunc) (ctx checkctx, done func()) {
+	ctx = checkctxs.register(&_checkctx{report: report, log: log})
+	return ctx, func() { checkctxs.deregister(ctx) }
 }
 
-func (ctx readerctx) register(fn readerfunc) {
-	readerctxmlock.Lock()
-	if _, ok := readerctxm[ctx]; ok {
-		readerctxmlock.Unlock()
-		panic("readerfunc conflict")
+// mdbxgoCompareBridge provides a static C function for the trampolines of
+// the comparator slots, see OpenDBIWithCompare.  Even slots are for keys and
+// odd ones for data.  The result is clamped to the range of C.int.  A panic
+// in the comparator, or a comparator released while libmdbx still uses it,
+// is cached on the Env of the table and the pair compares equal.
+//
+//export mdbxgoCompareBridge
+func mdbxgoCompareBridge(slot C.int, a *C.char, alen C.size_t, b *C.char, blen C.size_t) (rc C.int) {
+	s := &cmpSlots[slot/2]
+	fn := s.key.Load()
+	if slot%2 != 0 {
+		fn = s.data.Load()
+	}
+	if fn == nil || *fn == nil {
+		s.fail(errCmpReleased)
+		return 0
 	}
-	readerctxm[ctx] = &_readerctx{fn: fn}
-	readerctxmlock.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			s.fail(fmt.Errorf("mdbx: panic in comparator: %v", r))
+			rc = 0
+		}
+	}()
+
+	c := (*fn)(castToBytesRaw(unsafe.Pointer(a), alen), castToBytesRaw(unsafe.Pointer(b), blen))
+	switch {
+	case c < 0:
+		return -1
+	case c > 0:
+		return 1
+	}
+	return 0
 }
 
-func (ctx readerctx) deregister() {
-	readerctxmlock.Lock()
-	delete(readerctxm, ctx)
-	readerctxmlock.Unlock()
+// userctx is the handle of an Env, or of a Txn or Cursor with user data.  It
+// is set as the user context of the C object, so that a callback given the C
+// object can find the Go one.  See handles.
+type userctx uintptr
+type _userctx struct {
+	obj  any // *Env, *Txn or *Cursor
+	data any
 }
 
-func (ctx readerctx) get() *_readerctx {
-	readerctxmlock.RLock()
-	_ctx := readerctxm[ctx]
-	readerctxmlock.RUnlock()
-	return _ctx
+var userctxs handles[userctx, _userctx]
+
+// setUserData attaches data to obj, whose handle is *ctx, and passes a new
+// handle to set, which makes it the user context of the C object.  A nil data
+// releases the handle, and the user context is set to zero.
+func setUserData(ctx *userctx, obj, data any, set func(C.size_t) C.int) C.int {
+	if data != nil && *ctx != 0 {
+		userctxs.store(*ctx, &_userctx{obj: obj, data: data})
+		return success
+	}
+	var h userctx
+	if data != nil {
+		h = userctxs.register(&_userctx{obj: obj, data: data})
+	}
+	if ret := set(C.size_t(h)); ret != success {
+		userctxs.deregister(h)
+		return ret
+	}
+	ctx.release()
+	*ctx = h
+	return success
+}
+
+// data returns the user data attached with the handle ctx.
+func (ctx userctx) data() any {
+	if _ctx := userctxs.get(ctx); _ctx != nil {
+		return _ctx.data
+	}
+	return nil
+}
+
+// release drops the handle *ctx once its C object is gone.
+func (ctx *userctx) release() {
+	userctxs.deregister(*ctx)
+	*ctx = 0
+}
+
+// userObject returns the Go object of the user context h of a C object, or
+// nil.
+func userObject(h userctx) any {
+	if _ctx := userctxs.get(h); _ctx != nil {
+		return _ctx.obj
+	}
+	return nil
 }
@@ -54,6 +54,9 @@ type ReaderStats struct {
 // BytesRetained is the approximate amount of data prevented from reuse by the
 // reader's MVCC snapshot.
 func (env *Env) ReaderList(fn func(ReaderInfo) error) error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
 	if fn == nil {
 		return errNilReaderListFunc
 	}
@@ -61,7 +64,7 @@ func (env *Env) ReaderList(fn func(ReaderInfo) error) error {
 	defer done()
 
 	ret := C.mdbxgo_reader_list(env._env, C.size_t(ctx))
-	if ctxerr := ctx.get().err; ctxerr != nil {
+	if ctxerr := readerctxs.get(ctx).err; ctxerr != nil {
 		return ctxerr
 	}
 	return operrno("mdbx_reader_list", ret)
@@ -0,0 +1,129 @@
+package mdbx
+
+/*
+#include <stdlib.h>
+#include "mdbxgo.h"
+*/
+import "C"
+
+import "unsafe"
+
+// NumMetas is the number of meta pages of a database, which in turn reference
+// the most recent commits.  Meta pages are numbered from 0 to NumMetas-1 in
+// the arguments of the recovery functions and the index of EnvInfo.Metas.
+const NumMetas = 3
+
+// MetaPages returns the meta pages of the database at path, for choosing the
+// one to recover to.  The meta page with the greatest TxnID is the one an
+// ordinary Open uses, provided it is steady or written in the current boot,
+// see MetaInfo.State.  flags may hold NoSubdir, as for Open, the other flags
+// are ignored.  For an Env already open the meta pages are in EnvInfo.Metas.
+//
+// MetaPages opens the database read-only for the purpose, so it works while
+// other processes have it open.  After an unclean shutdown leaving a weak
+// head meta page, which a read-only open refuses with WannaRecovery,
+// MetaPages opens the database for recovery instead, which needs it to be
+// closed by all processes.
+func MetaPages(path string, flags uint) ([NumMetas]MetaInfo, error) {
+	flags &= NoSubdir
+	metas, err := metaPages(func(env *Env) error {
+		return env.Open(path, Readonly|flags, 0)
+	})
+	if !IsErrno(err, WannaRecovery) {
+		return metas, err
+	}
+	// any meta page lets the database be opened to read all of them.
+	for i := range NumMetas {
+		metas, err = metaPages(func(env *Env) error {
+			if err := env.SetFlags(flags); err != nil {
+				return err
+			}
+			return env.OpenForRecovery(path, i, false)
+		})
+		if err == nil {
+			break
+		}
+	}
+	return metas, err
+}
+
+func metaPages(open func(env *Env) error) ([NumMetas]MetaInfo, error) {
+	env, err := NewEnv(Default)
+	if err != nil {
+		return [NumMetas]MetaInfo{}, err
+	}
+	defer env.Close()
+	if err = open(env); err != nil {
+		return [NumMetas]MetaInfo{}, err
+	}
+	info, err := env.Info(nil)
+	if err != nil {
+		return [NumMetas]MetaInfo{}, err
+	}
+	return info.Metas, nil
+}
+
+// OpenForRecovery creates an Env and opens the database at path with the
+// given meta page, see Env.OpenForRecovery.  Options such as OptMaxDB can't
+// be set before the open this way, so to open named tables create the Env
+// with NewEnv and call its OpenForRecovery instead.
+func OpenForRecovery(path string, targetMeta int, writable bool) (*Env, error) {
+	env, err := NewEnv(Default)
+	if err != nil {
+		return nil, err
+	}
+	if err = env.OpenForRecovery(path, targetMeta, writable); err != nil {
+		env.Close()
+		return nil, err
+	}
+	return env, nil
+}
+
+// OpenForRecovery opens the environment handle like Open, but with the MVCC
+// snapshot referenced by the meta page numbered targetMeta rather than the
+// most recent one, for inspecting or reverting to an older commit.  The
+// database is opened in Exclusive mode, and read-only unless writable is set.
+// A writable Env can be turned to the snapshot for good with TurnForRecovery.
+// Flags set by SetFlags before, such as NoSubdir, are kept.
+//
+// Usually only the snapshot of the commit before the most recent one is
+// intact, as the pages of older ones may have been reused since, which then
+// surfaces as a Corrupted or Panic error.  Write transactions on an older
+// snapshot overwrite the pages of the newer ones.  This is the mode of the
+// mdbx_chk tool.
+//
+// See mdbx_env_open_for_recovery.
+func (env *Env) OpenForRecovery(path string, targetMeta int, writable bool) error {
+	if targetMeta < 0 || targetMeta >= NumMetas {
+		return operrno("mdbx_env_open_for_recovery", C.MDBX_EINVAL)
+	}
+	// mdbx_env_open_for_recovery takes no flags but keeps those set, so
+	// NoStickyThreads is set here as in Open.
+	ret := C.mdbx_env_set_flags(env._env, NoStickyThreads, true)
+	if ret != success {
+		return operrno("mdbx_env_set_flags", ret)
+	}
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+	env.forks = C.mdbxgo_forks
+	ret = C.mdbx_env_open_for_recovery(env._env, cpath, C.unsigned(targetMeta), C.bool(writable))
+	return operrno("mdbx_env_open_for_recovery", ret)
+}
+
+// TurnForRecovery makes the meta page numbered targetMeta the most recent one
+// so that the next Open, by any process, uses its snapshot and the newer
+// commits are lost.  The Env must be opened by OpenForRecovery with writable
+// set, otherwise TurnForRecovery fails with EPERM.  targetMeta may differ from
+// the meta page the Env was opened with.
+//
+// See mdbx_env_turn_for_recovery.
+func (env *Env) TurnForRecovery(targetMeta int) error {
+	if err := env.checkFork(); err != nil {
+		return err
+	}
+	if targetMeta < 0 || targetMeta >= NumMetas {
+		return operrno("mdbx_env_turn_for_recovery", C.MDBX_EINVAL)
+	}
+	ret := C.mdbx_env_turn_for_recovery(env._env, C.unsigned(targetMeta))
+	return operrno("mdbx_env_turn_for_recovery", ret)
+}
@@ -0,0 +1,227 @@
+package mdbx
+
+import (
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// setupRecovery commits a value to table "t" in five transactions and
+// returns the closed env's path and the value committed by each txn ID.
+func setupRecovery(t *testing.T) (path string, values map[uint64]uint64) {
+	t.Helper()
+	env, path := setup(t)
+	values = make(map[uint64]uint64)
+	for i := range uint64(5) {
+		err := env.Update(func(txn *Txn) error {
+			db, err := txn.OpenDBISimple("t", Create)
+			if err != nil {
+				return err
+			}
+			values[txn.ID()] = i
+			return txn.Put(db, []byte("k"), binary.BigEndian.AppendUint64(nil, i), 0)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := env.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path, values
+}
+
+func recoveryValue(t *testing.T, env *Env) uint64 {
+	t.Helper()
+	var v uint64
+	err := env.View(func(txn *Txn) error {
+		db, err := txn.OpenDBISimple("t", 0)
+		if err != nil {
+			return err
+		}
+		val, err := txn.Get(db, []byte("k"))
+		if err != nil {
+			return err
+		}
+		v = binary.BigEndian.Uint64(val)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestMetaPages(t *testing.T) {
+	path, values := setupRecovery(t)
+	metas, err := MetaPages(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range metas {
+		if _, ok := values[m.TxnID]; !ok {
+			t.Errorf("meta %d: unexpected txn %d", i, m.TxnID)
+		}
+		if m.State() != MetaSteady {
+			t.Errorf("meta %d: unexpected state %v", i, m.State())
+		}
+	}
+}
+
+// TestMetaPages_unclean lists the meta pages of a database left by a process
+// which committed with UtterlyNoSync and exited without closing it, which a
+// read-only open refuses.
+func TestMetaPages_unclean(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMetaPages_uncleanHelper$")
+	cmd.Env = append(os.Environ(), "MDBXGO_UNCLEAN_PATH="+path)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("helper: %v", err)
+	}
+	lines := strings.Fields(string(out))
+	if len(lines) == 0 {
+		t.Fatal("no output of the helper")
+	}
+	head, err := strconv.ParseUint(lines[len(lines)-1], 10, 64)
+	if err != nil {
+		t.Fatalf("helper output %q: %v", out, err)
+	}
+
+	env, err := NewEnv(Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.Open(path, Readonly|NoSubdir, 0)
+	env.Close()
+	if !IsErrno(err, WannaRecovery) {
+		t.Fatalf("unexpected error of a read-only open: %v", err)
+	}
+
+	metas, err := MetaPages(path, NoSubdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, m := range metas {
+		if m.TxnID == head {
+			found = true
+			if m.State() != MetaWeak {
+				t.Errorf("unexpected state %v of the head meta", m.State())
+			}
+		}
+	}
+	if !found {
+		t.Errorf("no meta page of txn %d: %+v", head, metas)
+	}
+}
+
+// TestMetaPages_uncleanHelper runs in the child process of
+// TestMetaPages_unclean, and prints the ID of its last commit.
+func TestMetaPages_uncleanHelper(t *testing.T) {
+	path := os.Getenv("MDBXGO_UNCLEAN_PATH")
+	if path == "" {
+		t.Skip("helper process of TestMetaPages_unclean")
+	}
+	env, err := NewEnv(Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = env.Open(path, NoSubdir|UtterlyNoSync, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var head uint64
+	for i := range 3 {
+		err = env.Update(func(txn *Txn) error {
+			db, err := txn.OpenRoot(0)
+			if err != nil {
+				return err
+			}
+			head = txn.ID()
+			return txn.Put(db, []byte("k"), []byte{byte(i)}, 0)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Stdout.WriteString(strconv.FormatUint(head, 10) + "\n")
+	// exit without closing env, which would sync it.
+	os.Exit(0)
+}
+
+func TestOpenForRecovery(t *testing.T) {
+	path, values := setupRecovery(t)
+	metas, err := MetaPages(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the previous commit, the pages of older ones may have been reused.
+	var prev int
+	for i, m := range metas {
+		if values[m.TxnID] == 3 {
+			prev = i
+		}
+	}
+	expect := values[metas[prev].TxnID]
+	if expect != 3 {
+		t.Fatalf("no meta page of the previous commit: %+v", metas)
+	}
+
+	env, err := NewEnv(Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = env.SetOption(OptMaxDB, 8); err != nil {
+		t.Fatal(err)
+	}
+	if err = env.OpenForRecovery(path, prev, false); err != nil {
+		t.Fatal(err)
+	}
+	if v := recoveryValue(t, env); v != expect {
+		t.Errorf("read %d from the previous meta", v)
+	}
+	if err = env.TurnForRecovery(prev); !IsErrnoSys(err, syscall.EPERM) {
+		t.Errorf("unexpected error turning a read-only env: %v", err)
+	}
+	env.Close()
+
+	if _, err = OpenForRecovery(path, NumMetas, false); !IsErrnoSys(err, syscall.EINVAL) {
+		t.Errorf("unexpected error of an invalid meta: %v", err)
+	}
+
+	// roll back to the previous commit for good.
+	env, err = NewEnv(Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = env.SetOption(OptMaxDB, 8); err != nil {
+		t.Fatal(err)
+	}
+	if err = env.OpenForRecovery(path, prev, true); err != nil {
+		t.Fatal(err)
+	}
+	if err = env.TurnForRecovery(prev); err != nil {
+		t.Fatal(err)
+	}
+	env.Close()
+
+	env, err = NewEnv(Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+	if err = env.SetOption(OptMaxDB, 8); err != nil {
+		t.Fatal(err)
+	}
+	if err = env.Open(path, 0, 0o664); err != nil {
+		t.Fatal(err)
+	}
+	if v := recoveryValue(t, env); v != expect {
+		t.Errorf("read %d after the rollback", v)
+	}
+}
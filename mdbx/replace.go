@@ -0,0 +1,140 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import "unsafe"
+
+// replaceBufSize is the size of the buffer Replace first offers libmdbx for
+// the previous value.  Larger values take a second call.
+const replaceBufSize = 64
+
+// Replace stores val for key in dbi, like Put, and returns the value it
+// replaced, or nil if key was absent.  A nil val deletes key instead, which
+// then must be present.  Unlike a Get followed by a Put, Replace descends
+// the B-tree once.  The returned value is a copy that remains valid after
+// the transaction ends.
+//
+// flags are those of Put.  With NoOverwrite an existing value is returned
+// along with KeyExist, and with Current an absent key fails with
+// ErrNotFound.  In DupSort tables Replace changes or deletes the only value
+// of key and fails with MultiValue if there are more, see CompareAndSwap
+// for replacing one of several values.
+//
+// See mdbx_replace.
+func (txn *Txn) Replace(dbi DBI, key, val []byte, flags uint) (old []byte, err error) {
+	buf := make([]byte, replaceBufSize)
+	for {
+		r := txn.replace(dbi, key, val, buf, flags, 0)
+		switch {
+		case r.err == C.MDBX_RESULT_TRUE:
+			// the previous value is on a dirty page and larger than buf.
+			buf = make([]byte, r.vlen)
+			continue
+		case r.vbase == nil:
+			old = nil
+		case len(buf) > 0 && unsafe.Pointer(r.vbase) == unsafe.Pointer(&buf[0]):
+			old = buf[:r.vlen]
+		default:
+			old = append(buf[:0], castToBytesRaw(unsafe.Pointer(r.vbase), r.vlen)...)
+		}
+		return old, txn.operrno("mdbx_replace", r.err)
+	}
+}
+
+// ReplaceFunc is like Replace but passes the replaced value to preserve
+// instead of copying it.  preserve is not called if key was absent.  old is
+// valid only until preserve returns, since it may be on a page the update
+// overwrites, and preserve must not use txn.  A panic in preserve is
+// recovered and returned as an error, in which case it is undefined whether
+// the update took place, so the transaction should be aborted.
+//
+// See mdbx_replace_ex.
+func (txn *Txn) ReplaceFunc(dbi DBI, key, val []byte, flags uint, preserve func(old []byte)) error {
+	ctx, done := newPreserveFunc(preserve)
+	defer done()
+	r := txn.replace(dbi, key, val, nil, flags, ctx)
+	_ctx := preservectxs.get(ctx)
+	if _ctx.err != nil {
+		return _ctx.err
+	}
+	if !_ctx.called && r.vbase != nil {
+		// libmdbx preserves values only on dirty pages, values on other
+		// pages are left in place and returned.
+		mdbxgoPreserveBridge(C.size_t(ctx), (*C.char)(r.vbase), r.vlen)
+		if _ctx.err != nil {
+			return _ctx.err
+		}
+	}
+	return txn.operrno("mdbx_replace_ex", r.err)
+}
+
+func (txn *Txn) replace(dbi DBI, key, val, buf []byte, flags uint, ctx preservectx) C.mdbxgo_val_result {
+	if flags&(Current|NoOverwrite) == Current|NoOverwrite {
+		// this combination makes libmdbx take the previous value as input.
+		return C.mdbxgo_val_result{err: C.MDBX_EINVAL}
+	}
+	if val == nil {
+		flags |= Current
+	}
+	var k, v, o *C.char
+	if len(key) > 0 {
+		k = (*C.char)(unsafe.Pointer(&key[0]))
+	}
+	if len(val) > 0 {
+		v = (*C.char)(unsafe.Pointer(&val[0]))
+	}
+	if len(buf) > 0 {
+		o = (*C.char)(unsafe.Pointer(&buf[0]))
+	}
+	return C.mdbxgo_replace(
+		txn._txn, C.MDBX_dbi(dbi),
+		k, C.size_t(len(key)),
+		v, C.size_t(len(val)), C.bool(val == nil),
+		o, C.size_t(len(buf)),
+		C.MDBX_put_flags_t(flags), C.size_t(ctx),
+	)
+}
+
+// CompareAndSwap replaces the value of key in dbi with val if it equals
+// expected, and reports whether it did, looking key up once.  A nil expected
+// stores val only if key is absent, while an empty non-nil expected matches
+// a present empty value.  Likewise a nil val deletes the value, while an
+// empty one stores an empty value.  expected and val cannot both be nil.  A
+// missing key is no error, CompareAndSwap just returns false.
+//
+// In DupSort tables expected selects one of the values of key, which is
+// replaced by val or deleted, and a nil expected adds val to the values of
+// key unless present.
+//
+// See mdbx_replace_ex.
+func (txn *Txn) CompareAndSwap(dbi DBI, key, expected, val []byte) (swapped bool, err error) {
+	if expected == nil && val == nil {
+		return false, txn.operrno("mdbx_replace_ex", C.MDBX_EINVAL)
+	}
+	var k, e, v *C.char
+	if len(key) > 0 {
+		k = (*C.char)(unsafe.Pointer(&key[0]))
+	}
+	if len(expected) > 0 {
+		e = (*C.char)(unsafe.Pointer(&expected[0]))
+	}
+	if len(val) > 0 {
+		v = (*C.char)(unsafe.Pointer(&val[0]))
+	}
+	ret := C.mdbxgo_compare_and_swap(
+		txn._txn, C.MDBX_dbi(dbi),
+		k, C.size_t(len(key)),
+		e, C.size_t(len(expected)), C.bool(expected == nil),
+		v, C.size_t(len(val)), C.bool(val == nil),
+	)
+	switch {
+	case ret == C.MDBX_RESULT_TRUE, ret == C.MDBX_NOTFOUND:
+		return false, nil
+	case ret == C.MDBX_KEYEXIST && expected == nil:
+		return false, nil
+	}
+	return ret == success, txn.operrno("mdbx_replace_ex", ret)
+}
@@ -0,0 +1,254 @@
+package mdbx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTxn_Replace(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenUniqueDB(t, env, "replace")
+	large := bytes.Repeat([]byte("x"), 3*replaceBufSize)
+
+	err := env.Update(func(txn *Txn) error {
+		old, err := txn.Replace(db, []byte("k"), []byte("v1"), 0)
+		if err != nil || old != nil {
+			t.Errorf("insert: %q %v", old, err)
+		}
+		// the page is dirty now, so libmdbx copies the value.
+		if old, err = txn.Replace(db, []byte("k"), large, 0); err != nil || string(old) != "v1" {
+			t.Errorf("replace: %q %v", old, err)
+		}
+		if old, err = txn.Replace(db, []byte("k"), []byte("v2"), 0); err != nil || !bytes.Equal(old, large) {
+			t.Errorf("replace large: %q %v", old, err)
+		}
+		if old, err = txn.Replace(db, []byte("k"), []byte("v3"), NoOverwrite); !IsKeyExists(err) || string(old) != "v2" {
+			t.Errorf("no overwrite: %q %v", old, err)
+		}
+		if _, err = txn.Replace(db, []byte("absent"), []byte("v"), Current); !IsNotFound(err) {
+			t.Errorf("current: %v", err)
+		}
+		if _, err = txn.Replace(db, []byte("k"), []byte("v"), Current|NoOverwrite); err == nil {
+			t.Errorf("replace with Current|NoOverwrite succeeded")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a clean page
+	err = env.Update(func(txn *Txn) error {
+		old, err := txn.Replace(db, []byte("k"), []byte("v4"), 0)
+		if err != nil || string(old) != "v2" {
+			t.Errorf("replace: %q %v", old, err)
+		}
+		if old, err = txn.Replace(db, []byte("k"), nil, 0); err != nil || string(old) != "v4" {
+			t.Errorf("delete: %q %v", old, err)
+		}
+		if _, err = txn.Get(db, []byte("k")); !IsNotFound(err) {
+			t.Errorf("deleted key found: %v", err)
+		}
+		if _, err = txn.Replace(db, []byte("k"), nil, 0); !IsNotFound(err) {
+			t.Errorf("delete absent: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_ReplaceFunc(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenUniqueDB(t, env, "replace")
+
+	for _, clean := range []bool{false, true} {
+		var preserved []string
+		preserve := func(old []byte) { preserved = append(preserved, string(old)) }
+		err := env.Update(func(txn *Txn) error {
+			if err := txn.ReplaceFunc(db, []byte("k"), []byte("v1"), 0, preserve); err != nil {
+				return err
+			}
+			return txn.ReplaceFunc(db, []byte("k"), []byte("v2"), 0, preserve)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "v1"
+		// the second round starts on the clean page of the first.
+		if clean {
+			want = "v2 v1"
+		}
+		if got := strings.Join(preserved, " "); got != want {
+			t.Errorf("preserved %q (!= %q)", got, want)
+		}
+	}
+
+	err := env.Update(func(txn *Txn) error {
+		return txn.ReplaceFunc(db, []byte("k"), []byte("v3"), 0, func([]byte) { panic("oops") })
+	})
+	if err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTxn_CompareAndSwap(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenUniqueDB(t, env, "cas")
+
+	err := env.Update(func(txn *Txn) error {
+		for i, test := range []struct {
+			expected, val string
+			nilExpected   bool
+			nilVal        bool
+			swapped       bool
+			want          string // "" for absent
+		}{
+			{val: "v1", nilExpected: true, swapped: true, want: "v1"},
+			{val: "v2", nilExpected: true, want: "v1"},
+			{expected: "v2", val: "v3", want: "v1"},
+			{expected: "v1", val: "v2", swapped: true, want: "v2"},
+			{expected: "v1", nilVal: true, want: "v2"},
+			{expected: "v2", nilVal: true, swapped: true},
+			{expected: "v2", val: "v3"},
+		} {
+			expected, val := []byte(test.expected), []byte(test.val)
+			if test.nilExpected {
+				expected = nil
+			}
+			if test.nilVal {
+				val = nil
+			}
+			swapped, err := txn.CompareAndSwap(db, []byte("k"), expected, val)
+			if err != nil || swapped != test.swapped {
+				t.Errorf("%d: swapped %t: %v", i, swapped, err)
+			}
+			v, err := txn.Get(db, []byte("k"))
+			if (test.want == "" && !IsNotFound(err)) || string(v) != test.want {
+				t.Errorf("%d: %q %v (!= %q)", i, v, err, test.want)
+			}
+		}
+		if _, err := txn.CompareAndSwap(db, []byte("k"), nil, nil); err == nil {
+			t.Errorf("nil expected and val accepted")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_CompareAndSwap_empty(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenUniqueDB(t, env, "cas")
+
+	err := env.Update(func(txn *Txn) error {
+		k := []byte("k")
+		// an empty expected does not stand for an absent key.
+		if swapped, err := txn.CompareAndSwap(db, k, []byte{}, []byte("v")); err != nil || swapped {
+			t.Errorf("swap of an absent key: %t %v", swapped, err)
+		}
+		if swapped, err := txn.CompareAndSwap(db, k, nil, []byte{}); err != nil || !swapped {
+			t.Errorf("insert of an empty value: %t %v", swapped, err)
+		}
+		// nor does nil stand for an empty value.
+		if swapped, err := txn.CompareAndSwap(db, k, nil, []byte("v")); err != nil || swapped {
+			t.Errorf("insert over an empty value: %t %v", swapped, err)
+		}
+		if swapped, err := txn.CompareAndSwap(db, k, []byte{}, []byte("v")); err != nil || !swapped {
+			t.Errorf("swap of an empty value: %t %v", swapped, err)
+		}
+		if v, err := txn.Get(db, k); err != nil || string(v) != "v" {
+			t.Errorf("unexpected value %q %v", v, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestTxn_CompareAndSwap_committed swaps values on clean pages, which
+// libmdbx replaces without preserving them.
+func TestTxn_CompareAndSwap_committed(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenUniqueDB(t, env, "cas")
+	if err := env.Update(func(txn *Txn) error {
+		return txn.Put(db, []byte("k"), []byte("v1"), 0)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := env.Update(func(txn *Txn) error {
+		if swapped, err := txn.CompareAndSwap(db, []byte("k"), []byte("x"), []byte("v2")); err != nil || swapped {
+			t.Errorf("swap of another value: %t %v", swapped, err)
+		}
+		if v, err := txn.Get(db, []byte("k")); err != nil || string(v) != "v1" {
+			t.Errorf("unexpected value %q %v", v, err)
+		}
+		if swapped, err := txn.CompareAndSwap(db, []byte("k"), []byte("v1"), []byte("v2")); err != nil || !swapped {
+			t.Errorf("swap: %t %v", swapped, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_CompareAndSwap_dupSort(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenDupSortDB(t, env, "cas")
+
+	values := func(txn *Txn) string {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cur.Close()
+		var vals []string
+		for op := uint(Set); ; op = NextDup {
+			_, v, err := cur.Get([]byte("k"), nil, op)
+			if IsNotFound(err) {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			vals = append(vals, string(v))
+		}
+		return strings.Join(vals, " ")
+	}
+
+	err := env.Update(func(txn *Txn) error {
+		for _, v := range []string{"a", "c", "e"} {
+			if swapped, err := txn.CompareAndSwap(db, []byte("k"), nil, []byte(v)); err != nil || !swapped {
+				t.Fatalf("insert %s: %t %v", v, swapped, err)
+			}
+		}
+		if swapped, err := txn.CompareAndSwap(db, []byte("k"), nil, []byte("c")); err != nil || swapped {
+			t.Errorf("insert present: %t %v", swapped, err)
+		}
+		if swapped, err := txn.CompareAndSwap(db, []byte("k"), []byte("c"), []byte("f")); err != nil || !swapped {
+			t.Errorf("swap: %t %v", swapped, err)
+		}
+		if swapped, err := txn.CompareAndSwap(db, []byte("k"), []byte("b"), []byte("g")); err != nil || swapped {
+			t.Errorf("swap absent: %t %v", swapped, err)
+		}
+		if swapped, err := txn.CompareAndSwap(db, []byte("k"), []byte("a"), nil); err != nil || !swapped {
+			t.Errorf("delete: %t %v", swapped, err)
+		}
+		if got := values(txn); got != "e f" {
+			t.Errorf("values %q", got)
+		}
+
+		if _, err := txn.Replace(db, []byte("k"), []byte("x"), Current); !IsErrno(err, MultiValue) {
+			t.Errorf("replace a multi-value: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
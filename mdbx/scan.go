@@ -0,0 +1,141 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import "unsafe"
+
+// FieldOp is the comparison a KeyField or ValueField predicate makes between
+// a field and its operand.
+type FieldOp int
+
+// The comparisons of KeyField and ValueField.  The field is compared with the
+// operand bytewise, like bytes.Compare.
+const (
+	FieldEqual          FieldOp = C.MDBXGO_FIELD_EQ
+	FieldNotEqual       FieldOp = C.MDBXGO_FIELD_NE
+	FieldLess           FieldOp = C.MDBXGO_FIELD_LT
+	FieldLessOrEqual    FieldOp = C.MDBXGO_FIELD_LE
+	FieldGreater        FieldOp = C.MDBXGO_FIELD_GT
+	FieldGreaterOrEqual FieldOp = C.MDBXGO_FIELD_GE
+)
+
+// Predicate selects the pair a Cursor.Scan stops at.  All predicates but
+// those of PredicateFunc are evaluated in C, so the scan crosses into C only
+// once however many pairs it probes.
+//
+// A Predicate holds references to its operands, which must not be modified
+// while it is in use.
+type Predicate struct {
+	kind   C.int
+	cmp    FieldOp
+	offset int
+	a, b   []byte
+	fn     func(key, val []byte) bool
+}
+
+// KeyPrefix matches keys starting with prefix.
+func KeyPrefix(prefix []byte) Predicate {
+	return Predicate{kind: C.MDBXGO_PRED_KEY_PREFIX, a: prefix}
+}
+
+// KeyRange matches keys from from inclusive up to to exclusive, in
+// lexicographic byte order.  An empty to has no upper bound.
+func KeyRange(from, to []byte) Predicate {
+	return Predicate{kind: C.MDBXGO_PRED_KEY_RANGE, a: from, b: to}
+}
+
+// ValueEquals matches values equal to val.
+func ValueEquals(val []byte) Predicate {
+	return Predicate{kind: C.MDBXGO_PRED_VALUE_EQUAL, a: val}
+}
+
+// KeyField matches keys whose len(operand) bytes at offset compare to
+// operand as op says.  Keys too short to hold the field do not match.
+func KeyField(offset int, op FieldOp, operand []byte) Predicate {
+	return Predicate{kind: C.MDBXGO_PRED_KEY_FIELD, cmp: op, offset: offset, a: operand}
+}
+
+// ValueField is KeyField for values.
+func ValueField(offset int, op FieldOp, operand []byte) Predicate {
+	return Predicate{kind: C.MDBXGO_PRED_VALUE_FIELD, cmp: op, offset: offset, a: operand}
+}
+
+// PredicateFunc matches the pairs fn returns true for.  fn is called from C
+// for each pair probed, so it is much slower than the other predicates.  The
+// key and val passed to fn are only valid for the duration of the call.  A
+// panic in fn stops the scan and is returned as its error.
+func PredicateFunc(fn func(key, val []byte) bool) Predicate {
+	return Predicate{kind: C.MDBXGO_PRED_GO, fn: fn}
+}
+
+// Scan positions c by startOp and moves it by turnOp until p matches, and
+// returns the matching pair.  If no pair matches Scan returns ErrNotFound,
+// with c left at the end of the data.
+//
+// startOp is one of First, FirstDup, Last, LastDup, GetCurrent or
+// GetMultiple, and turnOp one of Next, NextDup, NextNoDup, Prev, PrevDup,
+// PrevNoDup, NextMultiple or PrevMultiple.  The returned key and val are
+// views into the database, see Get.
+//
+// See mdbx_cursor_scan.
+func (c *Cursor) Scan(p Predicate, startOp, turnOp uint) (key, val []byte, err error) {
+	return c.scan(p, false, startOp, nil, nil, turnOp)
+}
+
+// ScanFrom is Scan starting from the pair fromOp positions c at with
+// fromKey and fromVal, such as SetKey, SetLowerBound or GetBothRange.
+//
+// See mdbx_cursor_scan_from.
+func (c *Cursor) ScanFrom(p Predicate, fromOp uint, fromKey, fromVal []byte, turnOp uint) (key, val []byte, err error) {
+	return c.scan(p, true, fromOp, fromKey, fromVal, turnOp)
+}
+
+func (c *Cursor) scan(p Predicate, from bool, startOp uint, fromKey, fromVal []byte, turnOp uint) (key, val []byte, err error) {
+	var ctx scanctx
+	if p.fn != nil {
+		var done func()
+		ctx, done = newScanFunc(p.fn)
+		defer done()
+	}
+	var a, b, k, v *C.char
+	if len(p.a) > 0 {
+		a = (*C.char)(unsafe.Pointer(&p.a[0]))
+	}
+	if len(p.b) > 0 {
+		b = (*C.char)(unsafe.Pointer(&p.b[0]))
+	}
+	if len(fromKey) > 0 {
+		k = (*C.char)(unsafe.Pointer(&fromKey[0]))
+	}
+	if len(fromVal) > 0 {
+		v = (*C.char)(unsafe.Pointer(&fromVal[0]))
+	}
+	r := C.mdbxgo_cursor_scan(
+		c._c, p.kind, C.int(p.cmp), C.size_t(p.offset),
+		a, C.size_t(len(p.a)),
+		b, C.size_t(len(p.b)),
+		C.size_t(ctx), C.bool(from), C.MDBX_cursor_op(startOp),
+		k, C.size_t(len(fromKey)),
+		v, C.size_t(len(fromVal)),
+		C.MDBX_cursor_op(turnOp),
+	)
+	switch r.err {
+	case C.MDBX_RESULT_TRUE:
+		return castToBytesRaw(unsafe.Pointer(r.kbase), r.klen), castToBytesRaw(unsafe.Pointer(r.vbase), r.vlen), nil
+	case C.MDBX_RESULT_FALSE:
+		return nil, nil, ErrNotFound
+	case C.MDBXGO_SCAN_ABORT:
+		if ctx != 0 {
+			if err := scanctxs.get(ctx).err; err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if from {
+		return nil, nil, c.operrno("mdbx_cursor_scan_from", r.err)
+	}
+	return nil, nil, c.operrno("mdbx_cursor_scan", r.err)
+}
@@ -0,0 +1,271 @@
+package mdbx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCursor_Scan(t *testing.T) {
+	env, _ := setup(t)
+	const n = 1000
+	db := mustPutSeqBE(t, env, "scan", n)
+
+	err := env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for _, test := range []struct {
+			name   string
+			p      Predicate
+			start  uint
+			turn   uint
+			expect int // -1 for none
+		}{
+			{"prefix", KeyPrefix([]byte{0, 0, 2}), First, Next, 512},
+			{"prefix-empty", KeyPrefix(nil), First, Next, 0},
+			{"prefix-none", KeyPrefix([]byte{0, 1}), First, Next, -1},
+			{"prefix-prev", KeyPrefix([]byte{0, 0, 1}), Last, Prev, 511},
+			{"range", KeyRange(beKey(700), beKey(701)), First, Next, 700},
+			{"range-unbounded", KeyRange(beKey(998), nil), First, Next, 998},
+			{"range-empty", KeyRange(beKey(5), beKey(5)), First, Next, -1},
+			{"range-prev", KeyRange(nil, beKey(10)), Last, Prev, 9},
+			{"value", ValueEquals(beKey(321)), First, Next, 321},
+			{"value-none", ValueEquals(beKey(321)[1:]), First, Next, -1},
+			{"key-field", KeyField(3, FieldEqual, []byte{42}), First, Next, 42},
+			{"key-field-short", KeyField(3, FieldEqual, []byte{42, 0}), First, Next, -1},
+			{"key-field-ge", KeyField(2, FieldGreaterOrEqual, []byte{3, 0xe0}), First, Next, 992},
+			{"key-field-lt", KeyField(2, FieldLess, []byte{3}), Last, Prev, 767},
+			{"key-field-ne", KeyField(0, FieldNotEqual, []byte{0, 0, 0}), First, Next, 256},
+			{"value-field-gt", ValueField(3, FieldGreater, []byte{0xfe}), First, Next, 255},
+			{"value-field-le", ValueField(2, FieldLessOrEqual, []byte{2}), Last, Prev, 767},
+			{"func", PredicateFunc(func(k, v []byte) bool { return k[3] == 7 && v[2] == 1 }), First, Next, 263},
+		} {
+			k, v, err := cur.Scan(test.p, test.start, test.turn)
+			if test.expect < 0 {
+				if !IsNotFound(err) {
+					t.Errorf("%s: unexpected result: %x %v", test.name, k, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+				continue
+			}
+			if !bytes.Equal(k, beKey(uint32(test.expect))) || !bytes.Equal(v, k) {
+				t.Errorf("%s: found %x=%x", test.name, k, v)
+			}
+			// the cursor is left at the match.
+			if ck, _, err := cur.Get(nil, nil, GetCurrent); err != nil || !bytes.Equal(ck, k) {
+				t.Errorf("%s: cursor at %x (%v)", test.name, ck, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCursor_ScanFrom(t *testing.T) {
+	env, _ := setup(t)
+	db := mustPutSeqBE(t, env, "scan", 1000)
+
+	err := env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		// the pair scanned from is probed as well.
+		k, _, err := cur.ScanFrom(KeyPrefix([]byte{0, 0}), SetKey, beKey(300), nil, Next)
+		if err != nil || !bytes.Equal(k, beKey(300)) {
+			t.Errorf("unexpected result: %x %v", k, err)
+		}
+		k, _, err = cur.ScanFrom(KeyField(3, FieldEqual, []byte{0}), SetLowerBound, beKey(300), nil, Next)
+		if err != nil || !bytes.Equal(k, beKey(512)) {
+			t.Errorf("unexpected result: %x %v", k, err)
+		}
+		k, _, err = cur.ScanFrom(KeyField(3, FieldEqual, []byte{0}), SetLowerBound, beKey(300), nil, Prev)
+		if err != nil || !bytes.Equal(k, beKey(256)) {
+			t.Errorf("unexpected result: %x %v", k, err)
+		}
+		if k, _, err = cur.ScanFrom(ValueEquals(beKey(10)), SetLowerBound, beKey(11), nil, Next); !IsNotFound(err) {
+			t.Errorf("unexpected result: %x %v", k, err)
+		}
+		if k, _, err = cur.ScanFrom(KeyPrefix(nil), SetKey, beKey(5000), nil, Next); !IsNotFound(err) {
+			t.Errorf("unexpected result of a missing key: %x %v", k, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCursor_Scan_dupSort(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenDupSortDB(t, env, "dups")
+	err := env.Update(func(txn *Txn) error {
+		for _, k := range []string{"a", "b", "c"} {
+			for _, v := range []string{"1", "2", "3"} {
+				if err := txn.Put(db, []byte(k), []byte(k+v), 0); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		k, v, err := cur.Scan(ValueEquals([]byte("b3")), First, Next)
+		if err != nil || string(k) != "b" || string(v) != "b3" {
+			t.Errorf("unexpected result: %q=%q %v", k, v, err)
+		}
+		// NextNoDup only probes the first value of each key.
+		if k, v, err = cur.Scan(ValueEquals([]byte("b3")), First, NextNoDup); !IsNotFound(err) {
+			t.Errorf("unexpected result: %q=%q %v", k, v, err)
+		}
+		// NextDup stays within the values of the key at hand.
+		if _, _, err = cur.Get([]byte("c"), nil, SetKey); err != nil {
+			return err
+		}
+		if k, v, err = cur.Scan(ValueEquals([]byte("b3")), GetCurrent, NextDup); !IsNotFound(err) {
+			t.Errorf("unexpected result: %q=%q %v", k, v, err)
+		}
+		k, v, err = cur.ScanFrom(ValueField(1, FieldGreater, []byte("1")), GetBoth, []byte("c"), []byte("c1"), NextDup)
+		if err != nil || string(k) != "c" || string(v) != "c2" {
+			t.Errorf("unexpected result: %q=%q %v", k, v, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCursor_Scan_func(t *testing.T) {
+	env, _ := setup(t)
+	db := mustPutSeqBE(t, env, "scan", 100)
+
+	err := env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		var probed int
+		_, _, err = cur.Scan(PredicateFunc(func(k, v []byte) bool {
+			probed++
+			return false
+		}), First, Next)
+		if !IsNotFound(err) || probed != 100 {
+			t.Errorf("probed %d pairs: %v", probed, err)
+		}
+
+		_, _, err = cur.Scan(PredicateFunc(func(k, v []byte) bool {
+			if k[3] == 50 {
+				panic("boom")
+			}
+			return false
+		}), First, Next)
+		if err == nil || !strings.Contains(err.Error(), "boom") {
+			t.Errorf("unexpected error of a panic: %v", err)
+		}
+		// the scan stopped at the pair that panicked.
+		if k, _, err := cur.Get(nil, nil, GetCurrent); err != nil || !bytes.Equal(k, beKey(50)) {
+			t.Errorf("cursor at %x (%v)", k, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkCursor_Scan(b *testing.B) {
+	env, _ := setup(b)
+	const n = 100000
+	var db DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		db, err = txn.OpenDBISimple("scan", Create)
+		if err != nil {
+			return err
+		}
+		for i := range uint32(n) {
+			if err := txn.Put(db, beKey(i), beKey(i), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	last := beKey(n - 1)
+
+	for _, bench := range []struct {
+		name string
+		p    Predicate
+	}{
+		{"C", ValueEquals(last)},
+		{"Go", PredicateFunc(func(_, v []byte) bool { return bytes.Equal(v, last) })},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			err := env.View(func(txn *Txn) error {
+				cur, err := txn.OpenCursor(db)
+				if err != nil {
+					return err
+				}
+				defer cur.Close()
+				for b.Loop() {
+					if _, _, err := cur.Scan(bench.p, First, Next); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+	b.Run("Get", func(b *testing.B) {
+		err := env.View(func(txn *Txn) error {
+			cur, err := txn.OpenCursor(db)
+			if err != nil {
+				return err
+			}
+			defer cur.Close()
+			for b.Loop() {
+				for op := uint(First); ; op = Next {
+					_, v, err := cur.Get(nil, nil, op)
+					if err != nil {
+						return err
+					}
+					if bytes.Equal(v, last) {
+						break
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	})
+}
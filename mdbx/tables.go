@@ -0,0 +1,99 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"iter"
+)
+
+// TableInfo describes a named table of the database, as listed by
+// Txn.Tables.
+type TableInfo struct {
+	Name  string
+	Flags uint // Flags of the table, such as DupSort
+	// Stat holds the statistics of the table.  Stat.LastTxId is the ID of
+	// the transaction that last modified it.
+	Stat Stat
+	// DBI is the handle of the table if it is open in the environment and
+	// has been used in the transaction, otherwise 0.
+	DBI DBI
+}
+
+// Tables returns the named tables of the database along with their flags
+// and statistics, in the order of their names, without opening them.
+//
+// See mdbx_enumerate_tables.
+func (txn *Txn) Tables() ([]TableInfo, error) {
+	var tables []TableInfo
+	err := txn.ForEachTable(func(table TableInfo) error {
+		tables = append(tables, table)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// ForEachTable calls fn for each named table of the database, in the order
+// of their names, as Tables does without collecting them.  The enumeration
+// stops at the first error returned by fn, which ForEachTable returns.
+//
+// See mdbx_enumerate_tables.
+func (txn *Txn) ForEachTable(fn func(TableInfo) error) error {
+	ctx, done := newTableFunc(fn)
+	defer done()
+
+	ret := C.mdbxgo_enumerate_tables(txn._txn, C.size_t(ctx))
+	if ret == C.MDBX_RESULT_TRUE {
+		return tablectxs.get(ctx).err
+	}
+	return txn.operrno("mdbx_enumerate_tables", ret)
+}
+
+// AllTables iterates over the named tables of the database, in the order of
+// their names, as Tables does without collecting them.  The iterator is
+// returned with a function reporting the error which ended the last
+// iteration early, like the one of Txn.Range.
+//
+//	tables, errf := txn.AllTables()
+//	for table := range tables {
+//		...
+//	}
+//	if err := errf(); err != nil {
+//		...
+//	}
+//
+// See mdbx_enumerate_tables.
+func (txn *Txn) AllTables() (iter.Seq[TableInfo], func() error) {
+	var err error
+	seq := func(yield func(TableInfo) bool) {
+		// a panic in the loop body must not unwind through libmdbx, so it is
+		// carried over and raised again.
+		var panicked any
+		err = txn.ForEachTable(func(table TableInfo) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					panicked, err = r, errStopTables
+				}
+			}()
+			if !yield(table) {
+				return errStopTables
+			}
+			return nil
+		})
+		if panicked != nil {
+			panic(panicked)
+		}
+		if errors.Is(err, errStopTables) {
+			err = nil
+		}
+	}
+	return seq, func() error { return err }
+}
+
+var errStopTables = errors.New("mdbx: stop enumerating tables")
@@ -0,0 +1,127 @@
+package mdbx
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestTxn_Tables(t *testing.T) {
+	env, _ := setup(t)
+
+	var dups DBI
+	var dbis []DBI
+	var modified uint64
+	err := env.Update(func(txn *Txn) (err error) {
+		for i := range 3 {
+			db, err := txn.OpenDBISimple(fmt.Sprintf("table-%d", i), Create)
+			if err != nil {
+				return err
+			}
+			dbis = append(dbis, db)
+			for j := range i * 10 {
+				if err = txn.Put(db, fmt.Appendf(nil, "key-%d", j), []byte("value"), 0); err != nil {
+					return err
+				}
+			}
+		}
+		dups, err = txn.OpenDBISimple("dups", Create|DupSort)
+		modified = txn.ID()
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.CloseDBI(dups)
+
+	err = env.View(func(txn *Txn) error {
+		// use the last table in txn, which makes its handle known.
+		if _, err := txn.Get(dbis[2], []byte("key-0")); err != nil {
+			return err
+		}
+		tables, err := txn.Tables()
+		if err != nil {
+			return err
+		}
+		names, err := txn.ListDBI()
+		if err != nil {
+			return err
+		}
+		if len(tables) != len(names) {
+			t.Fatalf("tables %v (!= %v)", tables, names)
+		}
+		for i, table := range tables {
+			if table.Name != names[i] {
+				t.Errorf("table %d: %q (!= %q)", i, table.Name, names[i])
+			}
+			if table.Stat.LastTxId == 0 || table.Stat.LastTxId > modified || table.Stat.PSize != 4096 {
+				t.Errorf("table %q: unexpected stat %+v", table.Name, table.Stat)
+			}
+			if table.Name == "dups" {
+				if table.Flags&DupSort == 0 || table.DBI != 0 || table.Stat.Entries != 0 {
+					t.Errorf("unexpected table: %+v", table)
+				}
+				continue
+			}
+			var n int
+			if _, err = fmt.Sscanf(table.Name, "table-%d", &n); err != nil {
+				return err
+			}
+			if table.Flags&DupSort != 0 || table.Stat.Entries != uint64(n*10) || (table.DBI != 0 || n == 2) && table.DBI != dbis[n] {
+				t.Errorf("unexpected table: %+v", table)
+			}
+		}
+
+		stop := errors.New("stop")
+		var calls int
+		err = txn.ForEachTable(func(TableInfo) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("unexpected error after %d calls: %v", calls, err)
+		}
+
+		seq, errf := txn.AllTables()
+		var all []TableInfo
+		for table := range seq {
+			all = append(all, table)
+		}
+		if err := errf(); err != nil || !slices.Equal(all, tables) {
+			t.Errorf("unexpected iteration %v (!= %v): %v", all, tables, err)
+		}
+		for table := range seq {
+			if table.Name != tables[0].Name {
+				t.Errorf("unexpected first table %q", table.Name)
+			}
+			break
+		}
+		if err := errf(); err != nil {
+			t.Errorf("unexpected error after a break: %v", err)
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != stop {
+					t.Errorf("unexpected panic %v", r)
+				}
+			}()
+			for range seq {
+				panic(stop)
+			}
+		}()
+
+		// the stat of an open table is the same, table-2 sorting last.
+		stat, err := txn.StatDBI(dbis[2])
+		if err != nil {
+			return err
+		}
+		if last := tables[len(tables)-1]; *stat != last.Stat {
+			t.Errorf("unexpected stat %+v (!= %+v)", *stat, last.Stat)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -19,10 +19,6 @@ import (
 // This flags are used exclusively for Txn.OpenDBISimple and Txn.OpenRoot.  The
 // Create flag must always be supplied when opening a non-root DBI for the
 // first time.
-//
-// BUG(bmatsuo):
-// MDBX_INTEGERKEY and MDBX_INTEGERDUP aren't usable. I'm not sure they would be
-// faster with the cgo bridge.  They need to be tested and benchmarked.
 const (
 	// Flags for Txn.OpenDBI.
 
@@ -30,6 +26,8 @@ const (
 	DupSort    = C.MDBX_DUPSORT    // Use sorted duplicates.
 	DupFixed   = C.MDBX_DUPFIXED   // Duplicate items have a fixed size (DupSort).
 	ReverseDup = C.MDBX_REVERSEDUP // Reverse duplicate values (DupSort).
+	IntegerKey = C.MDBX_INTEGERKEY // Keys are native-endian uint32 or uint64, see PutUint64.
+	IntegerDup = C.MDBX_INTEGERDUP // Values are native-endian uint32 or uint64 (DupSort, DupFixed).
 	Create     = C.MDBX_CREATE     // Createt DB if not already existing.
 	DBAccede   = C.MDBX_DB_ACCEDE  // Use sorted duplicates.
 )
@@ -94,6 +92,12 @@ type Txn struct {
 	// therefore stops trusting the cache while parked.
 	parked bool
 
+	// broken is set by Break, so that Commit reports the txn libmdbx
+	// aborts instead.
+	broken bool
+
+	uctx userctx // see SetUserData
+
 	tid uint64
 }
 
@@ -109,6 +113,9 @@ func beginTxn(env *Env, parent *Txn, flags uint) (*Txn, error) {
 	if env.strictThreadCheck {
 		txn.tid = threads.CurrentThreadID()
 	}
+	if err := env.checkFork(); err != nil {
+		return nil, err
+	}
 
 	var ptxn *C.MDBX_txn
 	if parent != nil {
@@ -271,11 +278,23 @@ type CommitLatencyGC struct {
 
 func (txn *Txn) commit() (CommitLatency, error) {
 	txn.strictThreadCheck()
+	if err := txn.env.checkFork(); err != nil {
+		return CommitLatency{}, err
+	}
+	if err := txn.env.cmps.failure(); err != nil {
+		txn.abort()
+		return CommitLatency{}, err
+	}
 	r := C.mdbxgo_txn_commit_ex(txn._txn)
+	broken := txn.broken
 	txn.clearTxn()
 	s := buildCommitLatency(&r.lat)
+	if r.err == C.MDBX_RESULT_TRUE && broken {
+		// libmdbx aborts a broken txn and reports it as no error.
+		return s, &OpError{Op: "mdbx_txn_commit_ex", Errno: BadTxn}
+	}
 	if r.err != success {
-		return s, operrno("mdbx_txn_commit_ex", r.err)
+		return s, txn.operrno("mdbx_txn_commit_ex", r.err)
 	}
 	return s, nil
 }
@@ -348,7 +367,8 @@ func (txn *Txn) abort() {
 	if txn.env.strictThreadCheck {
 		txn.strictThreadCheck()
 	}
-	if txn.env._env != nil {
+	// a txn inherited over a fork is leaked unless the Env is resurrected.
+	if txn.env._env != nil && txn.env.checkFork() == nil {
 		C.mdbx_txn_abort(txn._txn)
 	}
 	txn.env.closeLock.RUnlock()
@@ -356,6 +376,27 @@ func (txn *Txn) abort() {
 	txn.clearTxn()
 }
 
+// Break marks txn, and its nested transactions, as broken after a failure
+// that leaves its changes inconsistent, so that they cannot be committed.
+// Any further operation on txn, including reads and cursor moves, fails with
+// BadTxn, while txn keeps its locks and snapshot until it is aborted.  Its
+// cursors may still be closed.  Commit aborts a broken txn and returns
+// BadTxn, so a TxnOp of Update which breaks its txn makes Update fail even if
+// the TxnOp returns nil.
+//
+// See mdbx_txn_break.
+func (txn *Txn) Break() error {
+	if err := txn.env.checkFork(); err != nil {
+		return err
+	}
+	ret := C.mdbx_txn_break(txn._txn)
+	if ret != success {
+		return txn.operrno("mdbx_txn_break", ret)
+	}
+	txn.broken = true
+	return nil
+}
+
 // Park puts a read-only transaction in the "parked" state so it does not
 // block recycling of old MVCC snapshots. Data pointers obtained before
 // parking must not be dereferenced until unparked. Write transactions cannot
@@ -383,9 +424,12 @@ func (txn *Txn) Park(autounpark bool) error {
 		return errNotOpen
 	}
 	txn.strictThreadCheck()
+	if err := txn.env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_txn_park(txn._txn, C.bool(autounpark))
 	if ret != success {
-		return operrno("mdbx_txn_park", ret)
+		return txn.operrno("mdbx_txn_park", ret)
 	}
 	txn.parked = true
 	return nil
@@ -416,6 +460,9 @@ func (txn *Txn) Unpark(restartIfOusted bool) (restarted bool, err error) {
 		return false, errNotOpen
 	}
 	txn.strictThreadCheck()
+	if err := txn.env.checkFork(); err != nil {
+		return false, err
+	}
 	ret := C.mdbx_txn_unpark(txn._txn, C.bool(restartIfOusted))
 	// Whatever the outcome, the txn is no longer parked: restored, restarted,
 	// reset by the oust, or never parked to begin with.
@@ -430,7 +477,7 @@ func (txn *Txn) Unpark(restartIfOusted bool) (restarted bool, err error) {
 		txn.resetID()
 	}
 	if ret != success {
-		return false, operrno("mdbx_txn_unpark", ret)
+		return false, txn.operrno("mdbx_txn_unpark", ret)
 	}
 	return false, nil
 }
@@ -440,6 +487,8 @@ func (txn *Txn) clearTxn() {
 	// pointer.
 	txn._txn = nil
 	txn.parked = false
+	txn.broken = false
+	txn.uctx.release()
 
 	// Clear txn.id because it no longer matches the value of txn._txn (and
 	// future calls to txn.ID() should not see the stale id).  Instead of
@@ -474,10 +523,14 @@ func (txn *Txn) Reset() error {
 }
 
 func (txn *Txn) reset() error {
+	if err := txn.env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_txn_reset(txn._txn)
 	txn.resetID()
 	txn.parked = false // a parked txn may be reset directly, which un-parks it
-	return operrno("mdbx_txn_reset", ret)
+	txn.broken = false
+	return txn.operrno("mdbx_txn_reset", ret)
 }
 
 // Renew reuses a transaction that was previously reset by calling txn.Reset().
@@ -493,6 +546,9 @@ func (txn *Txn) Renew() error {
 }
 
 func (txn *Txn) renew() error {
+	if err := txn.env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_txn_renew(txn._txn)
 
 	// mdbx_txn_renew causes txn._txn to pick up a new transaction ID.  It's
@@ -505,7 +561,7 @@ func (txn *Txn) renew() error {
 	txn.resetID()
 	txn.parked = false // renew restarts the reader, parked or not
 
-	return operrno("mdbx_txn_renew", ret)
+	return txn.operrno("mdbx_txn_renew", ret)
 }
 
 // Refresh advances a read-only transaction to the most recent MVCC-snapshot
@@ -520,12 +576,15 @@ func (txn *Txn) Refresh() (atTip bool, err error) {
 	if txn.managed {
 		panic("managed transaction cannot be refreshed directly")
 	}
+	if err := txn.env.checkFork(); err != nil {
+		return false, err
+	}
 	ret := C.mdbx_txn_refresh(txn._txn)
 	txn.resetID()
 	if ret == C.MDBX_RESULT_TRUE {
 		return true, nil
 	}
-	return false, operrno("mdbx_txn_refresh", ret)
+	return false, txn.operrno("mdbx_txn_refresh", ret)
 }
 
 // Checkpoint commits the operations of the write transaction and immediately
@@ -558,6 +617,13 @@ func (txn *Txn) Checkpoint(weakeningDurability uint) (lat CommitLatency, noChang
 		return CommitLatency{}, false, &OpError{Op: "mdbx_txn_checkpoint", Errno: BadTxn}
 	}
 	txn.strictThreadCheck()
+	if err := txn.env.checkFork(); err != nil {
+		return CommitLatency{}, false, err
+	}
+	if err := txn.env.cmps.failure(); err != nil {
+		txn.abort()
+		return CommitLatency{}, false, err
+	}
 	r := C.mdbxgo_txn_checkpoint(txn._txn, C.MDBX_txn_flags_t(weakeningDurability))
 	lat = buildCommitLatency(&r.lat)
 	txn.resetID()
@@ -572,7 +638,7 @@ func (txn *Txn) Checkpoint(weakeningDurability uint) (lat CommitLatency, noChang
 		// poison the wrapper's bookkeeping; we drop the reference to keep the
 		// post-error invariant of "handle is gone" symmetric with commit().
 		txn.clearTxn()
-		return lat, false, operrno("mdbx_txn_checkpoint", r.err)
+		return lat, false, txn.operrno("mdbx_txn_checkpoint", r.err)
 	}
 	return lat, false, nil
 }
@@ -608,6 +674,13 @@ func (txn *Txn) CommitEmbarkRead() (lat CommitLatency, noChanges bool, err error
 		return CommitLatency{}, false, &OpError{Op: "mdbx_txn_commit_embark_read", Errno: BadTxn}
 	}
 	txn.strictThreadCheck()
+	if err := txn.env.checkFork(); err != nil {
+		return CommitLatency{}, false, err
+	}
+	if err := txn.env.cmps.failure(); err != nil {
+		txn.abort()
+		return CommitLatency{}, false, err
+	}
 	r := C.mdbxgo_txn_commit_embark_read(&txn._txn)
 	lat = buildCommitLatency(&r.lat)
 	txn.resetID()
@@ -627,7 +700,7 @@ func (txn *Txn) CommitEmbarkRead() (lat CommitLatency, noChanges bool, err error
 		if txn._txn == nil {
 			txn.clearTxn()
 		}
-		return lat, false, operrno("mdbx_txn_commit_embark_read", r.err)
+		return lat, false, txn.operrno("mdbx_txn_commit_embark_read", r.err)
 	}
 	// Success: txn._txn now points to a freshly-started read-only txn.
 	txn.readonly = true
@@ -661,6 +734,9 @@ func (txn *Txn) Rollback() error {
 		return &OpError{Op: "mdbx_txn_rollback", Errno: BadTxn}
 	}
 	txn.strictThreadCheck()
+	if err := txn.env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_txn_rollback(txn._txn)
 	txn.resetID()
 	if ret == success || ret == C.MDBX_RESULT_TRUE {
@@ -673,7 +749,7 @@ func (txn *Txn) Rollback() error {
 	// preserve the pointer so the caller can Abort() — calling Abort on an
 	// already-finished handle is recoverable (BAD_TXN), calling it on a nil
 	// handle is silent and leaks the slot.
-	return operrno("mdbx_txn_rollback", ret)
+	return txn.operrno("mdbx_txn_rollback", ret)
 }
 
 // Amend promotes a read-only transaction into a write transaction that
@@ -704,6 +780,9 @@ func (txn *Txn) Amend(flags uint) (writeTxn *Txn, snapshotTooOld bool, err error
 	}
 	txn.strictThreadCheck()
 
+	if err := txn.env.checkFork(); err != nil {
+		return nil, false, err
+	}
 	newTxn := &Txn{
 		env:      txn.env,
 		readonly: false,
@@ -718,7 +797,7 @@ func (txn *Txn) Amend(flags uint) (writeTxn *Txn, snapshotTooOld bool, err error
 		return nil, true, nil
 	}
 	if ret != success {
-		return nil, false, operrno("mdbx_txn_amend", ret)
+		return nil, false, txn.operrno("mdbx_txn_amend", ret)
 	}
 	// Original read txn is consumed unless TxPrepareRO was requested.
 	if flags&TxPrepareRO == 0 {
@@ -742,6 +821,9 @@ func (txn *Txn) Amend(flags uint) (writeTxn *Txn, snapshotTooOld bool, err error
 //
 //nolint:gocritic // false positive on dupSubExpr
 func (txn *Txn) Clone() (*Txn, error) {
+	if err := txn.env.checkFork(); err != nil {
+		return nil, err
+	}
 	cloned := &Txn{
 		env:      txn.env,
 		readonly: true,
@@ -751,7 +833,7 @@ func (txn *Txn) Clone() (*Txn, error) {
 	}
 	ret := C.mdbx_txn_clone(txn._txn, &cloned._txn, nil)
 	if ret != success {
-		return nil, operrno("mdbx_txn_clone", ret)
+		return nil, txn.operrno("mdbx_txn_clone", ret)
 	}
 	return cloned, nil
 }
@@ -773,11 +855,19 @@ func (txn *Txn) CloneInto(target *Txn) error {
 	if target == nil || target._txn == nil || !target.readonly {
 		return &OpError{Op: "mdbx_txn_clone", Errno: BadTxn}
 	}
+	if err := txn.env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_txn_clone(txn._txn, &target._txn, nil)
 	if ret != success {
-		return operrno("mdbx_txn_clone", ret)
+		return txn.operrno("mdbx_txn_clone", ret)
 	}
 	target.resetID()
+	// libmdbx gives the clone the nil user context passed.
+	if target.uctx != 0 {
+		ret = C.mdbxgo_txn_set_userctx(target._txn, C.size_t(target.uctx))
+		return txn.operrno("mdbx_txn_set_userctx", ret)
+	}
 	return nil
 }
 
@@ -829,7 +919,7 @@ func (txn *Txn) CreateDBI(name string) (DBI, error) {
 // Flags returns the database flags for handle dbi.
 func (txn *Txn) Flags(dbi DBI) (uint, error) {
 	r := C.mdbxgo_dbi_flags(txn._txn, C.MDBX_dbi(dbi))
-	err := operrno("mdbx_dbi_flags", r.err)
+	err := txn.operrno("mdbx_dbi_flags", r.err)
 	if err != nil {
 		return 0, err
 	}
@@ -843,6 +933,46 @@ func (txn *Txn) OpenRoot(flags uint) (DBI, error) {
 	return txn.openDBISimple(nil, flags)
 }
 
+// coreDBs is the number of tables libmdbx keeps for itself, the GC and the
+// root table, whose handles are below any handle of a named table.
+const coreDBs = 2
+
+// RenameDBI renames the named table of dbi to newName, which must not be
+// taken by another table, otherwise an error with KeyExist is returned (see
+// IsKeyExists).  The root table cannot be renamed, RenameDBI returns
+// ErrRenameRoot for it.  dbi stays valid and refers to the renamed table.
+// Along with Drop it allows a table rebuilt under a temporary name to replace
+// another within one transaction.
+//
+// Unlike OpenDBISimple, RenameDBI does not stop at null bytes in newName.
+//
+// See mdbx_dbi_rename.
+func (txn *Txn) RenameDBI(dbi DBI, newName string) error {
+	return txn.renameDBI(dbi, unsafe.StringData(newName), len(newName))
+}
+
+// RenameDBIBytes is like RenameDBI but takes the new name as a byte slice,
+// e.g. as listed by Txn.ListDBI or Txn.Tables.
+//
+// See mdbx_dbi_rename2.
+func (txn *Txn) RenameDBIBytes(dbi DBI, newName []byte) error {
+	return txn.renameDBI(dbi, unsafe.SliceData(newName), len(newName))
+}
+
+func (txn *Txn) renameDBI(dbi DBI, name *byte, n int) error {
+	if dbi < coreDBs {
+		return ErrRenameRoot
+	}
+	if n == 0 {
+		return txn.operrno("mdbx_dbi_rename", C.MDBX_EINVAL)
+	}
+	ret := C.mdbxgo_dbi_rename(txn._txn, C.MDBX_dbi(dbi), (*C.char)(unsafe.Pointer(name)), C.size_t(n))
+	if ret == success {
+		txn.env.cmps.rename(dbi, string(unsafe.Slice(name, n)))
+	}
+	return txn.operrno("mdbx_dbi_rename", ret)
+}
+
 type Cmp func(k1, k2 []byte) int
 
 // openDBI returns whatever DBI value was set by mdbx_open_dbi.  In an
@@ -855,7 +985,10 @@ type Cmp func(k1, k2 []byte) int
 func (txn *Txn) openDBI(cname *C.char, flags uint, cmp, dcmp *C.MDBX_cmp_func) (DBI, error) {
 	r := C.mdbxgo_dbi_open_ex(txn._txn, cname, C.MDBX_db_flags_t(flags), cmp, dcmp)
 	if r.err != success {
-		return 0, operrno("mdbx_dbi_open", r.err)
+		return 0, txn.operrno("mdbx_dbi_open", r.err)
+	}
+	if cmp != nil {
+		txn.env.cmps.setForeign(DBI(r.val))
 	}
 	return DBI(r.val), nil
 }
@@ -863,7 +996,7 @@ func (txn *Txn) openDBI(cname *C.char, flags uint, cmp, dcmp *C.MDBX_cmp_func) (
 func (txn *Txn) openDBISimple(cname *C.char, flags uint) (DBI, error) {
 	r := C.mdbxgo_dbi_open(txn._txn, cname, C.MDBX_db_flags_t(flags))
 	if r.err != success {
-		return 0, operrno("mdbx_dbi_open", r.err)
+		return 0, txn.operrno("mdbx_dbi_open", r.err)
 	}
 	return DBI(r.val), nil
 }
@@ -922,7 +1055,7 @@ type GCInfo struct {
 func (txn *Txn) GCInfo() (*GCInfo, error) {
 	r := C.mdbxgo_gc_info(txn._txn)
 	if r.err != success {
-		return nil, operrno("mdbx_gc_info", r.err)
+		return nil, txn.operrno("mdbx_gc_info", r.err)
 	}
 	return &GCInfo{
 		PagesAllocated:   uint64(r.pages_allocated),
@@ -943,10 +1076,13 @@ func (txn *Txn) GCInfo() (*GCInfo, error) {
 //	if corresponding fields are not needed.
 //	See description of \ref MDBX_txn_info.
 func (txn *Txn) Info(scanRlt bool) (*TxInfo, error) {
+	if err := txn.env.checkFork(); err != nil {
+		return nil, err
+	}
 	var _stat C.MDBX_txn_info
 	ret := C.mdbx_txn_info(txn._txn, &_stat, C.bool(scanRlt))
 	if ret != success {
-		return nil, operrno("mdbx_txn_info", ret)
+		return nil, txn.operrno("mdbx_txn_info", ret)
 	}
 	return &TxInfo{
 		Id:             uint64(_stat.txn_id),
@@ -964,14 +1100,9 @@ func (txn *Txn) StatDBI(dbi DBI) (*Stat, error) {
 	var _stat C.MDBX_stat
 	ret := C.mdbx_dbi_stat(txn._txn, C.MDBX_dbi(dbi), &_stat, C.size_t(unsafe.Sizeof(_stat)))
 	if ret != success {
-		return nil, operrno("mdbx_dbi_stat", ret)
-	}
-	stat := Stat{PSize: uint(_stat.ms_psize),
-		Depth:         uint(_stat.ms_depth),
-		BranchPages:   uint64(_stat.ms_branch_pages),
-		LeafPages:     uint64(_stat.ms_leaf_pages),
-		OverflowPages: uint64(_stat.ms_overflow_pages),
-		Entries:       uint64(_stat.ms_entries)}
+		return nil, txn.operrno("mdbx_dbi_stat", ret)
+	}
+	stat := castStat(&_stat)
 	return &stat, nil
 }
 
@@ -981,7 +1112,7 @@ func (txn *Txn) StatDBI(dbi DBI) (*Stat, error) {
 // See mdbx_drop.
 func (txn *Txn) Drop(dbi DBI, del bool) error {
 	ret := C.mdbx_drop(txn._txn, C.MDBX_dbi(dbi), C.bool(del))
-	return operrno("mdbx_drop", ret)
+	return txn.operrno("mdbx_drop", ret)
 }
 
 // Sub executes fn in a subtransaction.  Sub commits the subtransaction iff a
@@ -1034,7 +1165,7 @@ func (txn *Txn) Get(dbi DBI, key []byte) ([]byte, error) {
 		k, C.size_t(len(key)),
 		&txn.val,
 	)
-	err := operrno("mdbx_get", ret)
+	err := txn.operrno("mdbx_get", ret)
 	if err != nil {
 		txn.val = C.MDBX_val{}
 		return nil, err
@@ -1044,6 +1175,44 @@ func (txn *Txn) Get(dbi DBI, key []byte) ([]byte, error) {
 	return b, nil
 }
 
+// GetEx is Get that also returns the number of values of key, which is
+// more than one only in DupSort tables, where val is the first of them.
+// Like Get it returns ErrNotFound if key is absent, with a nil val and a
+// zero count.
+//
+// See mdbx_get_ex.
+func (txn *Txn) GetEx(dbi DBI, key []byte) (val []byte, count uint64, err error) {
+	var k *C.char
+	if len(key) > 0 {
+		k = (*C.char)(unsafe.Pointer(&key[0]))
+	}
+	r := C.mdbxgo_get_ex(txn._txn, C.MDBX_dbi(dbi), k, C.size_t(len(key)))
+	if err := txn.operrno("mdbx_get_ex", r.err); err != nil {
+		return nil, 0, err
+	}
+	return castToBytesRaw(unsafe.Pointer(r.vbase), r.vlen), uint64(r.count), nil
+}
+
+// GetGreaterOrEqual retrieves the first pair of dbi whose key is equal to or
+// greater than key, like a cursor Get with SetLowerBound, and returns
+// ErrNotFound if there is none.  In DupSort tables val is the first value of
+// the key found.  The returned key and val are views into the database, see
+// Get.
+//
+// See mdbx_get_equal_or_great.
+func (txn *Txn) GetGreaterOrEqual(dbi DBI, key []byte) (found, val []byte, err error) {
+	var k *C.char
+	if len(key) > 0 {
+		k = (*C.char)(unsafe.Pointer(&key[0]))
+	}
+	r := C.mdbxgo_get_equal_or_great(txn._txn, C.MDBX_dbi(dbi), k, C.size_t(len(key)))
+	// MDBX_RESULT_TRUE reports a greater key, which operrno takes for success.
+	if err := txn.operrno("mdbx_get_equal_or_great", r.err); err != nil {
+		return nil, nil, err
+	}
+	return castToBytesRaw(unsafe.Pointer(r.kbase), r.klen), castToBytesRaw(unsafe.Pointer(r.vbase), r.vlen), nil
+}
+
 // Put stores an item in database dbi.
 //
 // See mdbx_put.
@@ -1061,7 +1230,7 @@ func (txn *Txn) Put(dbi DBI, key, val []byte, flags uint) error {
 		v, C.size_t(len(val)),
 		C.MDBX_put_flags_t(flags),
 	)
-	return operrno("mdbx_put", ret)
+	return txn.operrno("mdbx_put", ret)
 }
 
 // PutReserve returns a []byte of length n that can be written to, potentially
@@ -1081,7 +1250,7 @@ func (txn *Txn) PutReserve(dbi DBI, key []byte, n int, flags uint) ([]byte, erro
 		&txn.val,
 		C.MDBX_put_flags_t(flags|C.MDBX_RESERVE),
 	)
-	err := operrno("mdbx_put", ret)
+	err := txn.operrno("mdbx_put", ret)
 	if err != nil {
 		txn.val = C.MDBX_val{}
 		return nil, err
@@ -1108,7 +1277,7 @@ func (txn *Txn) Del(dbi DBI, key, val []byte) error {
 		k, C.size_t(len(key)),
 		v, C.size_t(len(val)),
 	)
-	return operrno("mdbx_del", ret)
+	return txn.operrno("mdbx_del", ret)
 }
 
 // OpenCursor allocates and initializes a Cursor to database dbi.
@@ -1118,6 +1287,17 @@ func (txn *Txn) OpenCursor(dbi DBI) (*Cursor, error) {
 	return openCursor(txn, dbi)
 }
 
+// operrno is operrno for the operations on txn.  In a child of a fork they
+// fail with EPERM until the Env is resurrected, and report ErrForked instead;
+// checking only on failure keeps the check off the hot path.
+func (txn *Txn) operrno(op string, ret C.int) error {
+	err := operrno(op, ret)
+	if err != nil && txn.env != nil && txn.env.checkFork() != nil {
+		return ErrForked
+	}
+	return err
+}
+
 func (txn *Txn) errf(format string, v ...any) {
 	if txn.errLogf != nil {
 		txn.errLogf(format, v...)
@@ -1215,7 +1395,7 @@ func (txn *Txn) EstimateRange(dbi DBI, beginKey, beginData, endKey, endData []by
 		bk, C.size_t(len(beginKey)), bd, C.size_t(len(beginData)),
 		ek, C.size_t(len(endKey)), ed, C.size_t(len(endData)),
 	)
-	if err := operrno("mdbx_estimate_range", r.err); err != nil {
+	if err := txn.operrno("mdbx_estimate_range", r.err); err != nil {
 		return 0, err
 	}
 	return int(r.val), nil
@@ -1224,7 +1404,7 @@ func (txn *Txn) EstimateRange(dbi DBI, beginKey, beginData, endKey, endData []by
 func (txn *Txn) Sequence(dbi DBI, increment uint64) (uint64, error) {
 	r := C.mdbxgo_dbi_sequence(txn._txn, C.MDBX_dbi(dbi), C.uint64_t(increment))
 	if r.err != success {
-		return uint64(r.val), operrno("mdbx_dbi_sequence", r.err)
+		return uint64(r.val), txn.operrno("mdbx_dbi_sequence", r.err)
 	}
 	return uint64(r.val), nil
 }
@@ -1250,12 +1430,15 @@ func (txn *Txn) ListDBI() (res []string, err error) {
 }
 
 func (txn *Txn) EnvWarmup(flags uint, timeout time.Duration) error {
+	if err := txn.env.checkFork(); err != nil {
+		return err
+	}
 	ret := C.mdbx_env_warmup(
 		txn.env._env, txn._txn,
 		C.MDBX_warmup_flags_t(flags),
 		C.uint(NewDuration16dot16(timeout)),
 	)
-	return operrno("mdbx_env_warmup", ret)
+	return txn.operrno("mdbx_env_warmup", ret)
 }
 
 func (txn *Txn) CHandle() unsafe.Pointer {
@@ -1271,7 +1454,7 @@ func (txn *Txn) CHandle() unsafe.Pointer {
 func (txn *Txn) ReleaseAllCursors(unbind bool) error {
 	ret := C.mdbx_txn_release_all_cursors(txn._txn, C.bool(unbind))
 	if ret != success {
-		return operrno("mdbx_txn_release_all_cursors", ret)
+		return txn.operrno("mdbx_txn_release_all_cursors", ret)
 	}
 	return nil
 }
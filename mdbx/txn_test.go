@@ -166,6 +166,77 @@ func TestTxn_Drop(t *testing.T) {
 	}
 }
 
+func TestTxn_RenameDBI(t *testing.T) {
+	env, _ := setup(t)
+
+	// rebuild "db" under a temporary name and swap it in.
+	var tmp DBI
+	err := env.Update(func(txn *Txn) error {
+		db, err := txn.CreateDBI("db")
+		if err != nil {
+			return err
+		}
+		if err = txn.Put(db, []byte("k"), []byte("old"), 0); err != nil {
+			return err
+		}
+		if tmp, err = txn.CreateDBI("db.tmp"); err != nil {
+			return err
+		}
+		if err = txn.Put(tmp, []byte("k"), []byte("new"), 0); err != nil {
+			return err
+		}
+		if err = txn.RenameDBI(tmp, "db"); !IsKeyExists(err) {
+			t.Errorf("rename over an existing table: %v", err)
+		}
+		if err = txn.Drop(db, true); err != nil {
+			return err
+		}
+		return txn.RenameDBI(tmp, "db")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.Update(func(txn *Txn) error {
+		names, err := txn.ListDBI()
+		if err != nil {
+			return err
+		}
+		if len(names) != 1 || names[0] != "db" {
+			t.Errorf("unexpected tables: %q", names)
+		}
+		if v, err := txn.Get(tmp, []byte("k")); err != nil || string(v) != "new" {
+			t.Errorf("renamed table: %q %v", v, err)
+		}
+		db, err := txn.OpenDBISimple("db", 0)
+		if err != nil || db != tmp {
+			t.Errorf("renamed table opened as %d (!= %d): %v", db, tmp, err)
+		}
+
+		if err = txn.RenameDBIBytes(tmp, []byte("db\x00bytes")); err != nil {
+			return err
+		}
+		if names, err = txn.ListDBI(); err != nil || len(names) != 1 || names[0] != "db\x00bytes" {
+			t.Errorf("unexpected tables: %q %v", names, err)
+		}
+
+		root, err := txn.OpenRoot(0)
+		if err != nil {
+			return err
+		}
+		if err = txn.RenameDBI(root, "root"); !errors.Is(err, ErrRenameRoot) {
+			t.Errorf("rename root: %v", err)
+		}
+		if err = txn.RenameDBI(tmp, ""); err == nil {
+			t.Errorf("empty name accepted")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestTxn_Del(t *testing.T) {
 	env, _ := setup(t)
 
@@ -2194,3 +2265,185 @@ func TestTxn_Reset_ReturnsError(t *testing.T) {
 		t.Errorf("Renew after Reset: %v", err)
 	}
 }
+
+func TestTxn_GetEx(t *testing.T) {
+	env, _ := setup(t)
+	db := mustPutSeqBE(t, env, "seq", 10)
+	dups := mustOpenDupSortDB(t, env, "dups")
+	err := env.Update(func(txn *Txn) error {
+		for _, v := range []string{"3", "1", "2"} {
+			if err := txn.Put(dups, []byte("k"), []byte(v), 0); err != nil {
+				return err
+			}
+		}
+		return txn.Put(dups, []byte("single"), []byte("v"), 0)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		val, count, err := txn.GetEx(db, beKey(4))
+		if err != nil || count != 1 || !bytes.Equal(val, beKey(4)) {
+			t.Errorf("unexpected result: %x %d %v", val, count, err)
+		}
+		val, count, err = txn.GetEx(dups, []byte("k"))
+		if err != nil || count != 3 || string(val) != "1" {
+			t.Errorf("unexpected result of dups: %q %d %v", val, count, err)
+		}
+		val, count, err = txn.GetEx(dups, []byte("single"))
+		if err != nil || count != 1 || string(val) != "v" {
+			t.Errorf("unexpected result of a single dup: %q %d %v", val, count, err)
+		}
+		if val, count, err = txn.GetEx(db, beKey(10)); !IsNotFound(err) || val != nil || count != 0 {
+			t.Errorf("unexpected result of a missing key: %x %d %v", val, count, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_GetGreaterOrEqual(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenUniqueDB(t, env, "sparse")
+	dups := mustOpenDupSortDB(t, env, "dups")
+	err := env.Update(func(txn *Txn) error {
+		for i := uint32(0); i < 100; i += 10 {
+			if err := txn.Put(db, beKey(i), beKey(i+1), 0); err != nil {
+				return err
+			}
+		}
+		for _, v := range []string{"2", "1"} {
+			if err := txn.Put(dups, []byte("b"), []byte(v), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		for _, test := range []struct {
+			key, expect uint32
+		}{
+			{0, 0},
+			{1, 10},
+			{30, 30},
+			{89, 90},
+		} {
+			k, v, err := txn.GetGreaterOrEqual(db, beKey(test.key))
+			if err != nil || !bytes.Equal(k, beKey(test.expect)) || !bytes.Equal(v, beKey(test.expect+1)) {
+				t.Errorf("key %d: %x=%x %v", test.key, k, v, err)
+			}
+		}
+		if k, v, err := txn.GetGreaterOrEqual(db, nil); err != nil || !bytes.Equal(k, beKey(0)) {
+			t.Errorf("unexpected result of an empty key: %x=%x %v", k, v, err)
+		}
+		if k, v, err := txn.GetGreaterOrEqual(db, beKey(91)); !IsNotFound(err) {
+			t.Errorf("unexpected result past the end: %x=%x %v", k, v, err)
+		}
+
+		for _, key := range []string{"a", "b"} {
+			k, v, err := txn.GetGreaterOrEqual(dups, []byte(key))
+			if err != nil || string(k) != "b" || string(v) != "1" {
+				t.Errorf("dups %q: %q=%q %v", key, k, v, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_Break(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenUniqueDB(t, env, "broken")
+
+	err := env.Update(func(txn *Txn) error {
+		if err := txn.Put(db, []byte("k"), []byte("v"), 0); err != nil {
+			return err
+		}
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		if _, _, err := cur.Get(nil, nil, First); err != nil {
+			return err
+		}
+		if err := txn.Break(); err != nil {
+			return err
+		}
+		if err := txn.Put(db, []byte("k2"), nil, 0); !IsErrno(err, BadTxn) {
+			t.Errorf("unexpected result of a put: %v", err)
+		}
+		if _, err := txn.Get(db, []byte("k")); !IsErrno(err, BadTxn) {
+			t.Errorf("unexpected result of a get: %v", err)
+		}
+		if _, _, err := cur.Get(nil, nil, GetCurrent); !IsErrno(err, BadTxn) {
+			t.Errorf("unexpected result of a cursor get: %v", err)
+		}
+		return nil
+	})
+	if !IsErrno(err, BadTxn) {
+		t.Errorf("unexpected result of an update with a broken txn: %v", err)
+	}
+
+	// a broken nested txn leaves its parent usable.
+	err = env.Update(func(txn *Txn) error {
+		err := txn.Sub(func(sub *Txn) error {
+			if err := sub.Put(db, []byte("sub"), nil, 0); err != nil {
+				return err
+			}
+			return sub.Break()
+		})
+		if !IsErrno(err, BadTxn) {
+			t.Errorf("unexpected result of a broken sub txn: %v", err)
+		}
+		return txn.Put(db, []byte("parent"), nil, 0)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		for key, found := range map[string]bool{"k": false, "sub": false, "parent": true} {
+			if _, err := txn.Get(db, []byte(key)); IsNotFound(err) == found {
+				t.Errorf("key %q: unexpected result %v", key, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a read txn is usable again once renewed.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	rtxn, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rtxn.Abort()
+	if err := rtxn.Break(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rtxn.Get(db, []byte("parent")); !IsErrno(err, BadTxn) {
+		t.Errorf("unexpected result of a get: %v", err)
+	}
+	if err := rtxn.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rtxn.Renew(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rtxn.Get(db, []byte("parent")); err != nil {
+		t.Errorf("unexpected result after renew: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+// The user data of an Env, Txn or Cursor is any value the application wants
+// to carry along with it.  It stays in Go memory: libmdbx is only given an
+// integer handle as the user context of the C object.  Every Env has a handle
+// from NewEnv on, by which callbacks given the C env, such as the
+// Handle-Slow-Readers one, find the Env.  A Txn or Cursor has one while it
+// has user data.
+
+// SetUserData attaches data to env, replacing the data attached before.  A
+// nil data detaches it.  The data is dropped when env is closed.
+//
+// See mdbx_env_set_userctx.
+func (env *Env) SetUserData(data any) error {
+	if env.uctx == 0 {
+		return errNotOpen
+	}
+	userctxs.store(env.uctx, &_userctx{obj: env, data: data})
+	return nil
+}
+
+// UserData returns the data attached to env by SetUserData, or nil.
+//
+// See mdbx_env_get_userctx.
+func (env *Env) UserData() any {
+	return env.uctx.data()
+}
+
+// SetUserData attaches data to txn, replacing the data attached before.  A
+// nil data detaches it.  The data is kept by Reset and Renew, and dropped
+// when txn ends.
+//
+// See mdbx_txn_set_userctx.
+func (txn *Txn) SetUserData(data any) error {
+	ret := setUserData(&txn.uctx, txn, data, func(h C.size_t) C.int {
+		return C.mdbxgo_txn_set_userctx(txn._txn, h)
+	})
+	return txn.operrno("mdbx_txn_set_userctx", ret)
+}
+
+// UserData returns the data attached to txn by SetUserData, or nil.
+//
+// See mdbx_txn_get_userctx.
+func (txn *Txn) UserData() any {
+	return txn.uctx.data()
+}
+
+// SetUserData attaches data to c, replacing the data attached before.  A nil
+// data detaches it.  The data is kept while c is bound to other transactions,
+// and dropped when c is closed or put in the pool by CursorToPool.
+//
+// See mdbx_cursor_set_userctx.
+func (c *Cursor) SetUserData(data any) error {
+	ret := setUserData(&c.uctx, c, data, func(h C.size_t) C.int {
+		return C.mdbxgo_cursor_set_userctx(c._c, h)
+	})
+	return c.operrno("mdbx_cursor_set_userctx", ret)
+}
+
+// UserData returns the data attached to c by SetUserData, or nil.
+//
+// See mdbx_cursor_get_userctx.
+func (c *Cursor) UserData() any {
+	return c.uctx.data()
+}
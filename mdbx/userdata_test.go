@@ -0,0 +1,184 @@
+package mdbx
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestEnv_UserData(t *testing.T) {
+	env, _ := setup(t)
+	if data := env.UserData(); data != nil {
+		t.Errorf("unexpected initial data %v", data)
+	}
+	// every env has a handle, for the callbacks given the C env.
+	if found := userObject(env.uctx); found != env {
+		t.Errorf("unexpected env %p of the handle, expected %p", found, env)
+	}
+	if err := env.SetUserData("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.SetUserData("b"); err != nil {
+		t.Fatal(err)
+	}
+	if data := env.UserData(); data != "b" {
+		t.Errorf("unexpected data %v", data)
+	}
+	if err := env.SetUserData(nil); err != nil {
+		t.Fatal(err)
+	}
+	if data, found := env.UserData(), userObject(env.uctx); data != nil || found != env {
+		t.Errorf("unexpected data %v and env %p after detaching", data, found)
+	}
+
+	if err := env.SetUserData(1); err != nil {
+		t.Fatal(err)
+	}
+	h := env.uctx
+	env.Close()
+	if data := env.UserData(); data != nil {
+		t.Errorf("unexpected data %v after close", data)
+	}
+	if userctxs.get(h) != nil {
+		t.Errorf("handle %d not released by close", h)
+	}
+	if err := env.SetUserData(2); err == nil {
+		t.Error("data attached to a closed env")
+	}
+}
+
+func TestTxn_UserData(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenUniqueDB(t, env, "userdata")
+
+	var wtxn *Txn
+	err := env.Update(func(txn *Txn) error {
+		wtxn = txn
+		if err := txn.SetUserData("w"); err != nil {
+			return err
+		}
+		err := txn.Sub(func(sub *Txn) error {
+			if sub.UserData() != nil || sub.uctx != 0 {
+				t.Error("user data inherited by a nested txn")
+			}
+			return sub.SetUserData("sub")
+		})
+		if err != nil {
+			return err
+		}
+		if found := userObject(txn.uctx); found != txn || txn.UserData() != "w" {
+			t.Errorf("unexpected txn %p with %v after a nested txn", found, txn.UserData())
+		}
+		return txn.Put(db, []byte("k"), []byte("v"), 0)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := wtxn.UserData(); data != nil {
+		t.Errorf("unexpected data %v after commit", data)
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	txn, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer txn.Abort()
+	if err := txn.SetUserData("r"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Renew(); err != nil {
+		t.Fatal(err)
+	}
+	if found := userObject(txn.uctx); found != txn || txn.UserData() != "r" {
+		t.Errorf("unexpected txn %p with %v after renew", found, txn.UserData())
+	}
+
+	// a clone has no data, and keeps its own when cloned into.
+	clone, err := txn.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clone.Abort()
+	if clone.UserData() != nil || clone.uctx != 0 {
+		t.Error("user data inherited by a clone")
+	}
+	if err := clone.SetUserData("c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := clone.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.CloneInto(clone); err != nil {
+		t.Fatal(err)
+	}
+	if found := userObject(clone.uctx); found != clone || clone.UserData() != "c" {
+		t.Errorf("unexpected txn %p with %v after a clone into it", found, clone.UserData())
+	}
+	if err := clone.SetUserData(nil); err != nil || clone.uctx != 0 {
+		t.Errorf("unexpected handle %d after detaching: %v", clone.uctx, err)
+	}
+}
+
+func TestCursor_UserData(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenUniqueDB(t, env, "userdata")
+
+	err := env.View(func(txn *Txn) error {
+		c1, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer c1.Close()
+		c2, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer c2.Close()
+		if err := c1.SetUserData(1); err != nil {
+			return err
+		}
+		if err := c2.SetUserData(2); err != nil {
+			return err
+		}
+		if userObject(c1.uctx) != c1 || userObject(c2.uctx) != c2 || c1.UserData() != 1 || c2.UserData() != 2 {
+			t.Error("cursors mixed up")
+		}
+
+		if err := c1.Unbind(); err != nil {
+			return err
+		}
+		if err := c1.Renew(txn); err != nil {
+			return err
+		}
+		if userObject(c1.uctx) != c1 || c1.UserData() != 1 {
+			t.Error("user data lost by rebinding")
+		}
+		clone, err := c1.Clone()
+		if err != nil {
+			return err
+		}
+		if clone.UserData() != nil || clone.uctx != 0 {
+			t.Error("user data inherited by a clone")
+		}
+		clone.Close()
+
+		h := c2.uctx
+		CursorToPool(c2)
+		if c2.UserData() != nil || userctxs.get(h) != nil {
+			t.Error("user data kept by a pooled cursor")
+		}
+		h = c1.uctx
+		c1.Close()
+		if c1.UserData() != nil || userctxs.get(h) != nil {
+			t.Error("user data kept by a closed cursor")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,83 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+// WriterLock is the writer lock of an Env taken by LockWriter.
+type WriterLock struct {
+	env *Env
+}
+
+// writerLockMaxWait bounds the interval at which LockWriter retries.
+const writerLockMaxWait = 50 * time.Millisecond
+
+// LockWriter takes the writer lock of env, which is shared by all processes
+// using the database, without beginning a write transaction, so that no
+// write transaction can begin until the lock is released by Unlock.
+// LockWriter waits for a write transaction in progress to end, or fails with
+// ctx.Err() once ctx is done.  The lock is not recursive.
+//
+// Like Update, LockWriter locks the calling goroutine to its thread, and
+// Unlock must be called from the same goroutine.  While holding the lock that
+// goroutine may call the env functions which take the writer lock, such as
+// SetGeometry, SetFlags, SetOption and Sync, see Open, but it must neither
+// begin a write transaction nor call LockWriter again, which would deadlock.
+// Other goroutines calling those functions wait for Unlock, as writers do, so
+// the holder must not wait for them.  Env.Close must not be called before
+// Unlock.
+//
+// See mdbx_txn_lock.
+func (env *Env) LockWriter(ctx context.Context) (*WriterLock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := env.checkFork(); err != nil {
+		return nil, err
+	}
+	runtime.LockOSThread()
+	for wait := time.Millisecond; ; wait = min(2*wait, writerLockMaxWait) {
+		// libmdbx cannot be interrupted while waiting for the lock, so it
+		// is polled.
+		ret := C.mdbx_txn_lock(env._env, true)
+		if ret == success {
+			return &WriterLock{env: env}, nil
+		}
+		if ret != C.MDBX_BUSY {
+			runtime.UnlockOSThread()
+			return nil, operrno("mdbx_txn_lock", ret)
+		}
+		select {
+		case <-ctx.Done():
+			runtime.UnlockOSThread()
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Unlock releases the writer lock and unlocks the calling goroutine from its
+// thread.  Called from another goroutine than the one which took the lock,
+// Unlock fails with ThreadMismatch and the lock stays held.  Once the lock is
+// released Unlock does nothing.
+//
+// See mdbx_txn_unlock.
+func (l *WriterLock) Unlock() error {
+	if l.env == nil {
+		return nil
+	}
+	ret := C.mdbx_txn_unlock(l.env._env)
+	if ret != success {
+		return operrno("mdbx_txn_unlock", ret)
+	}
+	l.env = nil
+	runtime.UnlockOSThread()
+	return nil
+}
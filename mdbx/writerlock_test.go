@@ -0,0 +1,124 @@
+package mdbx
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestEnv_LockWriter(t *testing.T) {
+	env, _ := setup(t)
+	db := mustOpenUniqueDB(t, env, "locked")
+
+	lock, err := env.LockWriter(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := make(chan error, 1)
+	go func() {
+		written <- env.Update(func(txn *Txn) error {
+			return txn.Put(db, []byte("k"), []byte("v"), 0)
+		})
+	}()
+	resized := make(chan error, 1)
+	go func() {
+		resized <- env.SetGeometry(-1, -1, 32<<20, -1, -1, -1)
+	}()
+
+	// the holder may call the env functions which take the writer lock.
+	if err := env.SetGeometry(-1, -1, 64<<20, -1, -1, -1); err != nil {
+		t.Errorf("set geometry: %v", err)
+	}
+	if err := env.Sync(true, false); err != nil {
+		t.Errorf("sync: %v", err)
+	}
+
+	// a second lock, and a lock from another goroutine, time out.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	if _, err := env.LockWriter(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected result of a second lock: %v", err)
+	}
+	cancel()
+	unlocked := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if _, err := env.LockWriter(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected result of a concurrent lock: %v", err)
+		}
+		unlocked <- lock.Unlock()
+	}()
+	if err := <-unlocked; !IsErrno(err, ThreadMismatch) {
+		t.Errorf("unexpected result of an unlock from another goroutine: %v", err)
+	}
+
+	// the writer and the other goroutine wait for the lock.
+	select {
+	case err := <-written:
+		t.Fatalf("write with the writer lock taken: %v", err)
+	case err := <-resized:
+		t.Fatalf("set geometry with the writer lock taken: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Errorf("unexpected result of a second unlock: %v", err)
+	}
+	for _, done := range []chan error{written, resized} {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Error(err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("blocked after unlock")
+		}
+	}
+}
+
+func TestEnv_LockWriter_txn(t *testing.T) {
+	env, _ := setup(t)
+
+	// a write txn in progress holds off LockWriter until it ends.
+	began := make(chan struct{})
+	end := make(chan struct{})
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		txn, err := env.BeginTxn(nil, 0)
+		close(began)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		<-end
+		txn.Abort()
+	}()
+	<-began
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := env.LockWriter(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected result with a write txn in progress: %v", err)
+	}
+	close(end)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	lock, err := env.LockWriter(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// done contexts fail before locking.
+	cancel()
+	if _, err := env.LockWriter(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected result with a done context: %v", err)
+	}
+}